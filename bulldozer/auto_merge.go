@@ -0,0 +1,194 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/shurcooL/githubv4"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+// EnablePullRequestAutoMergeInput is the input of the
+// enablePullRequestAutoMerge mutation. The githubv4 package does not define
+// its expectedHeadOid field yet; the GraphQL type of the input is derived from
+// the name of this type.
+type EnablePullRequestAutoMergeInput struct {
+	PullRequestID  githubv4.ID                      `json:"pullRequestId"`
+	CommitHeadline *githubv4.String                 `json:"commitHeadline,omitempty"`
+	CommitBody     *githubv4.String                 `json:"commitBody,omitempty"`
+	MergeMethod    *githubv4.PullRequestMergeMethod `json:"mergeMethod,omitempty"`
+
+	// ExpectedHeadOid makes GitHub reject the mutation if the head of the
+	// pull request changed since it was evaluated.
+	ExpectedHeadOid *githubv4.GitObjectID `json:"expectedHeadOid,omitempty"`
+}
+
+// AutoMerger manages GitHub's auto-merge on pull requests.
+type AutoMerger struct {
+	v4client *githubv4.Client
+}
+
+func NewAutoMerger(v4client *githubv4.Client) *AutoMerger {
+	return &AutoMerger{
+		v4client: v4client,
+	}
+}
+
+// AutoMergeState is the auto-merge state of a pull request.
+type AutoMergeState struct {
+	PullRequestID githubv4.ID
+
+	// Enabled is true if auto-merge is enabled on the pull request.
+	Enabled bool
+
+	// Mergeable is true if GitHub would merge the pull request right away,
+	// in which case it does not allow enabling auto-merge.
+	Mergeable bool
+}
+
+func (m *AutoMerger) State(ctx context.Context, pullCtx pull.Context) (*AutoMergeState, error) {
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				ID               githubv4.ID
+				MergeStateStatus string
+				AutoMergeRequest *struct {
+					MergeMethod string
+				}
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]interface{}{
+		"owner":  githubv4.String(pullCtx.Owner()),
+		"name":   githubv4.String(pullCtx.Repo()),
+		"number": githubv4.Int(pullCtx.Number()),
+	}
+	if err := m.v4client.Query(ctx, &q, vars); err != nil {
+		return nil, errors.Wrapf(err, "failed to get auto-merge state of %s", pullCtx.Locator())
+	}
+
+	pr := q.Repository.PullRequest
+	state := &AutoMergeState{
+		PullRequestID: pr.ID,
+		Enabled:       pr.AutoMergeRequest != nil,
+	}
+	switch pr.MergeStateStatus {
+	case "CLEAN", "HAS_HOOKS", "UNSTABLE":
+		state.Mergeable = true
+	}
+	return state, nil
+}
+
+// Enable enables auto-merge on the pull request with the given method and
+// commit message. GitHub rejects the mutation if the head of the pull request
+// is no longer headSHA.
+func (m *AutoMerger) Enable(ctx context.Context, state *AutoMergeState, method MergeMethod, msg CommitMessage, headSHA string) error {
+	head := githubv4.GitObjectID(headSHA)
+	input := EnablePullRequestAutoMergeInput{
+		PullRequestID:   state.PullRequestID,
+		ExpectedHeadOid: &head,
+	}
+
+	var mergeMethod githubv4.PullRequestMergeMethod
+	switch method {
+	case SquashAndMerge:
+		mergeMethod = githubv4.PullRequestMergeMethodSquash
+	case RebaseAndMerge:
+		mergeMethod = githubv4.PullRequestMergeMethodRebase
+	default:
+		mergeMethod = githubv4.PullRequestMergeMethodMerge
+	}
+	input.MergeMethod = &mergeMethod
+
+	if msg.Title != "" {
+		input.CommitHeadline = githubv4.NewString(githubv4.String(msg.Title))
+	}
+	if msg.Message != "" {
+		input.CommitBody = githubv4.NewString(githubv4.String(msg.Message))
+	}
+
+	var mutation struct {
+		EnablePullRequestAutoMerge struct {
+			ClientMutationID string
+		} `graphql:"enablePullRequestAutoMerge(input: $input)"`
+	}
+	return errors.Wrap(m.v4client.Mutate(ctx, &mutation, input, nil), "failed to enable auto-merge")
+}
+
+func (m *AutoMerger) Disable(ctx context.Context, state *AutoMergeState) error {
+	input := githubv4.DisablePullRequestAutoMergeInput{
+		PullRequestID: state.PullRequestID,
+	}
+
+	var mutation struct {
+		DisablePullRequestAutoMerge struct {
+			ClientMutationID string
+		} `graphql:"disablePullRequestAutoMerge(input: $input)"`
+	}
+	return errors.Wrap(m.v4client.Mutate(ctx, &mutation, input, nil), "failed to disable auto-merge")
+}
+
+// AutoMergePR enables auto-merge on the pull request if the decision passed,
+// so that GitHub merges it once it satisfies branch protection, and disables
+// auto-merge if the decision did not pass. Pull requests that GitHub would merge
+// right away are merged with the merger instead. It returns the outcome to
+// report, or nil if nothing changed.
+func AutoMergePR(ctx context.Context, pullCtx pull.Context, autoMerger *AutoMerger, merger Merger, mergeConfig MergeConfig, decision *Decision) (*MergeAttempt, error) {
+	logger := zerolog.Ctx(ctx)
+
+	state, err := autoMerger.State(ctx, pullCtx)
+	if err != nil {
+		return nil, err
+	}
+
+	// GitHub would merge a pull request with auto-merge enabled as soon as
+	// branch protection is satisfied, even if other conditions are pending
+	if decision.Result() != Pass {
+		if state.Enabled {
+			logger.Info().Msgf("Disabling auto-merge on %s because %s", pullCtx.Locator(), decision)
+			return nil, autoMerger.Disable(ctx, state)
+		}
+		return nil, nil
+	}
+
+	if state.Enabled {
+		return &MergeAttempt{AutoMerge: true}, nil
+	}
+
+	headSHA := pullCtx.HeadSHA()
+	method, msg, err := mergeMethodAndMessage(ctx, pullCtx, mergeConfig, headSHA)
+	if err != nil {
+		return nil, err
+	}
+
+	if state.Mergeable {
+		logger.Info().Msgf("Merging %s with method %s, as it can be merged right away", pullCtx.Locator(), method)
+		sha, err := merger.Merge(ctx, pullCtx, method, msg, headSHA)
+		if err == nil {
+			CleanupAfterMerge(ctx, pullCtx, merger, mergeConfig)
+		}
+		return newMergeAttempt(sha, err), nil
+	}
+
+	logger.Info().Msgf("Enabling auto-merge on %s with method %s", pullCtx.Locator(), method)
+	if err := autoMerger.Enable(ctx, state, method, msg, headSHA); err != nil {
+		return &MergeAttempt{Reason: errors.Cause(err).Error()}, err
+	}
+	return &MergeAttempt{AutoMerge: true}, nil
+}
@@ -0,0 +1,180 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+// graphQLServer answers queries with a fixed response and records the
+// variables of mutations.
+type graphQLServer struct {
+	*httptest.Server
+
+	Queries   int
+	Mutations []map[string]interface{}
+}
+
+func newGraphQLServer(t *testing.T, queryResponse, mutationResponse string) *graphQLServer {
+	s := &graphQLServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Query     string
+			Variables map[string]interface{}
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(body.Query, "mutation") {
+			s.Mutations = append(s.Mutations, body.Variables)
+			fmt.Fprint(w, mutationResponse)
+			return
+		}
+		s.Queries++
+		fmt.Fprint(w, queryResponse)
+	}))
+	return s
+}
+
+func (s *graphQLServer) Client() *githubv4.Client {
+	return githubv4.NewEnterpriseClient(s.URL, s.Server.Client())
+}
+
+func TestAutoMergePR(t *testing.T) {
+	ctx := context.Background()
+	pullCtx := &pulltest.MockPullContext{
+		OwnerValue:   "owner",
+		RepoValue:    "repo",
+		NumberValue:  7,
+		TitleValue:   "Fix the build",
+		HeadSHAValue: "abc",
+	}
+
+	passed := &Decision{Conditions: []Condition{{Name: "whitelist", Result: Pass}}}
+	pending := &Decision{Conditions: []Condition{{Name: `status "ci"`, Result: Pending}}}
+	failed := &Decision{Conditions: []Condition{{Name: "blacklist", Result: Fail}}}
+
+	const (
+		blocked         = `{"data": {"repository": {"pullRequest": {"id": "PR_1", "mergeStateStatus": "BLOCKED", "autoMergeRequest": null}}}}`
+		clean           = `{"data": {"repository": {"pullRequest": {"id": "PR_1", "mergeStateStatus": "CLEAN", "autoMergeRequest": null}}}}`
+		enabled         = `{"data": {"repository": {"pullRequest": {"id": "PR_1", "mergeStateStatus": "BLOCKED", "autoMergeRequest": {"mergeMethod": "SQUASH"}}}}}`
+		enableMutation  = `{"data": {"enablePullRequestAutoMerge": {"clientMutationId": null}}}`
+		disableMutation = `{"data": {"disablePullRequestAutoMerge": {"clientMutationId": null}}}`
+	)
+
+	squash := MergeConfig{
+		Method: SquashAndMerge,
+		Options: MergeOptions{
+			Squash: &SquashOptions{Title: PullRequestTitle, Body: EmptyBody},
+		},
+	}
+
+	t.Run("enable", func(t *testing.T) {
+		srv := newGraphQLServer(t, blocked, enableMutation)
+		defer srv.Close()
+
+		merger := &MockMerger{}
+		attempt, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), merger, squash, passed)
+		require.NoError(t, err)
+		assert.Equal(t, &MergeAttempt{AutoMerge: true}, attempt)
+		assert.Equal(t, 0, merger.MergeCount)
+
+		require.Len(t, srv.Mutations, 1)
+		assert.Equal(t, map[string]interface{}{
+			"pullRequestId":   "PR_1",
+			"mergeMethod":     "SQUASH",
+			"commitHeadline":  "Fix the build (#7)",
+			"commitBody":      " ",
+			"expectedHeadOid": "abc",
+		}, srv.Mutations[0]["input"])
+	})
+
+	t.Run("alreadyEnabled", func(t *testing.T) {
+		srv := newGraphQLServer(t, enabled, enableMutation)
+		defer srv.Close()
+
+		attempt, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), &MockMerger{}, squash, passed)
+		require.NoError(t, err)
+		assert.Equal(t, &MergeAttempt{AutoMerge: true}, attempt)
+		assert.Empty(t, srv.Mutations)
+	})
+
+	t.Run("mergeable", func(t *testing.T) {
+		srv := newGraphQLServer(t, clean, enableMutation)
+		defer srv.Close()
+
+		merger := &MockMerger{}
+		attempt, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), merger, MergeConfig{}, passed)
+		require.NoError(t, err)
+		assert.Equal(t, &MergeAttempt{Merged: true, SHA: "deadbeef"}, attempt)
+		assert.Equal(t, 1, merger.MergeCount, "mergeable pull requests are merged right away")
+		assert.Empty(t, srv.Mutations)
+	})
+
+	t.Run("pending", func(t *testing.T) {
+		srv := newGraphQLServer(t, enabled, disableMutation)
+		defer srv.Close()
+
+		attempt, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), &MockMerger{}, squash, pending)
+		require.NoError(t, err)
+		assert.Nil(t, attempt)
+		require.Len(t, srv.Mutations, 1, "auto-merge is disabled while conditions are pending")
+		assert.Equal(t, map[string]interface{}{"pullRequestId": "PR_1"}, srv.Mutations[0]["input"])
+	})
+
+	t.Run("pendingNotEnabled", func(t *testing.T) {
+		srv := newGraphQLServer(t, blocked, enableMutation)
+		defer srv.Close()
+
+		merger := &MockMerger{}
+		attempt, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), merger, squash, pending)
+		require.NoError(t, err)
+		assert.Nil(t, attempt)
+		assert.Equal(t, 0, merger.MergeCount)
+		assert.Empty(t, srv.Mutations)
+	})
+
+	t.Run("disable", func(t *testing.T) {
+		srv := newGraphQLServer(t, enabled, disableMutation)
+		defer srv.Close()
+
+		attempt, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), &MockMerger{}, squash, failed)
+		require.NoError(t, err)
+		assert.Nil(t, attempt)
+		require.Len(t, srv.Mutations, 1)
+		assert.Equal(t, map[string]interface{}{"pullRequestId": "PR_1"}, srv.Mutations[0]["input"])
+	})
+
+	t.Run("failedNotEnabled", func(t *testing.T) {
+		srv := newGraphQLServer(t, blocked, disableMutation)
+		defer srv.Close()
+
+		_, err := AutoMergePR(ctx, pullCtx, NewAutoMerger(srv.Client()), &MockMerger{}, squash, failed)
+		require.NoError(t, err)
+		assert.Empty(t, srv.Mutations)
+	})
+}
@@ -0,0 +1,569 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+// MergedBatch is a batch of the merge queue that was merged by
+// fast-forwarding the target branch to its staging commit.
+type MergedBatch struct {
+	Numbers []int
+	SHA     string
+}
+
+// StagingBranchPrefix is the prefix of the branches on which batches of
+// queued pull requests are tested. The name of the target branch follows the
+// prefix.
+const StagingBranchPrefix = "bulldozer/staging/"
+
+func StagingBranch(base string) string {
+	return StagingBranchPrefix + base
+}
+
+// Batch is a group of queued pull requests that are tested together by
+// merging them into the staging branch.
+type Batch struct {
+	Numbers   []int     `json:"numbers"`
+	StartedAt time.Time `json:"started_at"`
+
+	// BaseSHA is the commit of the target branch the batch is based on, and
+	// SHA the staging commit that merges all pull requests of the batch. Both
+	// are empty while the staging branch is prepared.
+	BaseSHA string `json:"base_sha,omitempty"`
+	SHA     string `json:"sha,omitempty"`
+
+	// Passed is true if the required statuses passed on the staging commit,
+	// but the target branch could not be fast-forwarded to it. The pull
+	// requests of the batch are then merged one at a time.
+	Passed bool `json:"passed"`
+
+	id    int
+	heads []string
+}
+
+func (b *Batch) contains(number int) bool {
+	for _, n := range b.Numbers {
+		if n == number {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *Batch) without(number int) {
+	for i, n := range b.Numbers {
+		if n == number {
+			b.Numbers = append(b.Numbers[:i:i], b.Numbers[i+1:]...)
+			if i < len(b.heads) {
+				b.heads = append(b.heads[:i:i], b.heads[i+1:]...)
+			}
+			return
+		}
+	}
+}
+
+func (b *Batch) copy() *Batch {
+	if b == nil {
+		return nil
+	}
+	c := *b
+	c.Numbers = append([]int(nil), b.Numbers...)
+	c.heads = append([]string(nil), b.heads...)
+	return &c
+}
+
+// startBatch returns the current batch of the queue. If there is none, it
+// starts a new batch with the first pull requests of the queue and returns
+// true; the caller must then prepare the staging branch.
+func (q *MergeQueue) startBatch(key QueueKey, size int) (*Batch, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if b := q.batches[key]; b != nil {
+		return b.copy(), false
+	}
+
+	entries := q.queues[key]
+	if len(entries) == 0 {
+		return nil, false
+	}
+
+	// after a failure, the batch is halved until the failing pull request
+	// is isolated
+	if limit := q.limits[key]; limit > 0 && limit < size {
+		size = limit
+	}
+	if size > len(entries) {
+		size = len(entries)
+	}
+
+	q.nextBatch++
+	b := &Batch{
+		StartedAt: time.Now(),
+		id:        q.nextBatch,
+	}
+	for i := range entries[:size] {
+		entries[i].State = "staging"
+		b.Numbers = append(b.Numbers, entries[i].Number)
+	}
+	q.batches[key] = b
+	return b.copy(), true
+}
+
+// batch returns the current batch of the queue, if it is the batch with the
+// given ID.
+func (q *MergeQueue) batch(key QueueKey, id int) *Batch {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if b := q.batches[key]; b != nil && b.id == id {
+		return b.copy()
+	}
+	return nil
+}
+
+// stageBatch records the staging commit of the batch and the pull requests
+// that were merged into it.
+func (q *MergeQueue) stageBatch(key QueueKey, id int, numbers []int, heads []string, baseSHA, sha string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := q.batches[key]
+	if b == nil || b.id != id {
+		return
+	}
+	b.Numbers = numbers
+	b.heads = heads
+	b.BaseSHA = baseSHA
+	b.SHA = sha
+	q.setBatchState(key, b, "testing")
+}
+
+func (q *MergeQueue) setBatchState(key QueueKey, b *Batch, state string) {
+	for i := range q.queues[key] {
+		if b.contains(q.queues[key][i].Number) {
+			q.queues[key][i].State = state
+		}
+	}
+}
+
+// cancelBatch discards the batch, so that the next evaluation starts a new
+// one.
+func (q *MergeQueue) cancelBatch(key QueueKey, id int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if b := q.batches[key]; b != nil && b.id == id {
+		q.setBatchState(key, b, "queued")
+		delete(q.batches, key)
+	}
+}
+
+// failBatch discards a batch that failed on the staging branch. If the batch
+// has a single pull request, it is returned so that it can be rejected.
+// Otherwise, the next batch is limited to half of the failed batch.
+func (q *MergeQueue) failBatch(key QueueKey, id int) (int, string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := q.batches[key]
+	if b == nil || b.id != id {
+		return 0, "", false
+	}
+	q.setBatchState(key, b, "queued")
+	delete(q.batches, key)
+
+	if len(b.Numbers) > 1 {
+		q.limits[key] = len(b.Numbers) / 2
+		return 0, "", false
+	}
+	delete(q.limits, key)
+
+	var head string
+	if len(b.heads) > 0 {
+		head = b.heads[0]
+	}
+	return b.Numbers[0], head, true
+}
+
+// passBatch marks a batch as passed, so that its pull requests are merged one
+// at a time.
+func (q *MergeQueue) passBatch(key QueueKey, id int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if b := q.batches[key]; b != nil && b.id == id {
+		b.Passed = true
+		q.setBatchState(key, b, "merging")
+		delete(q.limits, key)
+	}
+}
+
+// completeBatch removes the pull requests of a batch that was merged into the
+// target branch and returns the new head of the queue, if any.
+func (q *MergeQueue) completeBatch(key QueueKey, id int) (QueueEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	b := q.batches[key]
+	if b == nil || b.id != id {
+		return QueueEntry{}, false
+	}
+	delete(q.batches, key)
+	delete(q.limits, key)
+
+	for _, n := range b.Numbers {
+		q.remove(key, n)
+	}
+	return q.head(key)
+}
+
+// Head returns the first pull request of the queue, if any.
+func (q *MergeQueue) Head(key QueueKey) (QueueEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.head(key)
+}
+
+func (q *MergeQueue) head(key QueueKey) (QueueEntry, bool) {
+	if entries := q.queues[key]; len(entries) > 0 {
+		return entries[0], true
+	}
+	return QueueEntry{}, false
+}
+
+// BatchForCommit returns the queue whose batch is tested on the commit, so
+// that status events on the staging branch can evaluate the batch.
+func (q *MergeQueue) BatchForCommit(owner, repo, sha string) (QueueKey, QueueEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for key, b := range q.batches {
+		if key.Owner == owner && key.Repo == repo && b.SHA == sha && !b.Passed {
+			if head, ok := q.head(key); ok {
+				return key, head, true
+			}
+		}
+	}
+	return QueueKey{}, QueueEntry{}, false
+}
+
+// evaluateBatch adds the merge queue condition for a queued pull request in
+// batch mode. The first pull requests of the queue are merged into the
+// staging branch, and once the required statuses pass on the staging commit,
+// the target branch is fast-forwarded to it once every pull request of the
+// batch satisfies its merge conditions at the head that was tested. If the
+// statuses fail, the batch is split in half until the failing pull request is
+// found and rejected.
+func evaluateBatch(ctx context.Context, queue *MergeQueue, pullCtx pull.Context, client *github.Client, config MergeConfig, decision *Decision) (QueueEntry, bool, error) {
+	key := QueueKeyFor(pullCtx)
+	number := pullCtx.Number()
+	staging := StagingBranch(key.Base)
+
+	batch, started := queue.startBatch(key, config.Queue.BatchSize)
+	if started {
+		if err := buildBatch(ctx, queue, client, key, batch); err != nil {
+			queue.cancelBatch(key, batch.id)
+			return QueueEntry{}, false, err
+		}
+
+		if reason, ok := queue.rejection(key, number, pullCtx.HeadSHA()); ok {
+			decision.add("merge queue", Fail, reason)
+			return QueueEntry{}, false, nil
+		}
+
+		// all pull requests of the batch may have been rejected
+		batch = queue.batch(key, batch.id)
+		if batch == nil {
+			decision.add("merge queue", Pending, "pull request is waiting for a new batch of the merge queue")
+			next, ok := queue.Head(key)
+			return next, ok, nil
+		}
+	}
+
+	if batch == nil || !batch.contains(number) {
+		position, length := queue.Position(key, number)
+		decision.add("merge queue", Pending, fmt.Sprintf("pull request is at position %d of %d in the merge queue", position+1, length))
+		return QueueEntry{}, false, nil
+	}
+
+	if batch.SHA == "" {
+		decision.add("merge queue", Pending, fmt.Sprintf("pull request is in a batch that is being prepared on %s", staging))
+		return QueueEntry{}, false, nil
+	}
+
+	if batch.Passed {
+		mergeBatchMember(queue, key, number, batch, decision)
+		return QueueEntry{}, false, nil
+	}
+
+	statuses, err := pull.CommitStatuses(ctx, client, key.Owner, key.Repo, batch.SHA)
+	if err != nil {
+		return QueueEntry{}, false, errors.Wrapf(err, "failed to get statuses of %s", staging)
+	}
+	protected, err := pullCtx.RequiredStatuses(ctx)
+	if err != nil {
+		return QueueEntry{}, false, errors.Wrap(err, "failed to determine required Github status checks")
+	}
+	required := append(requiredStatusNames(protected), config.RequiredStatuses...)
+
+	staged := &Decision{}
+	if err := requiredStatusConditions(staged, required, statuses, config.StatusConclusions, nil); err != nil {
+		return QueueEntry{}, false, err
+	}
+
+	switch staged.Result() {
+	case Pending:
+		decision.add("merge queue", Pending, fmt.Sprintf("batch of %d pull requests is waiting for required statuses on %s", len(batch.Numbers), staging))
+		return QueueEntry{}, false, nil
+
+	case Fail:
+		culprit, head, isolated := queue.failBatch(key, batch.id)
+		if !isolated {
+			decision.add("merge queue", Pending, fmt.Sprintf("batch of %d pull requests failed on %s and is being split", len(batch.Numbers), staging))
+			next, ok := queue.Head(key)
+			return next, ok, nil
+		}
+
+		reason := fmt.Sprintf("pull request failed on %s: %s", staging, staged.Blocking()[0])
+		next, advance := queue.Reject(key, culprit, head, reason)
+		if culprit == number {
+			decision.add("merge queue", Fail, reason)
+		} else {
+			decision.add("merge queue", Pending, fmt.Sprintf("pull request #%d failed on %s and was removed from the merge queue", culprit, staging))
+		}
+		if !advance {
+			deleteStagingBranch(ctx, client, key)
+		}
+		return next, advance, nil
+	}
+
+	if decision.Result() != Pass {
+		decision.add("merge queue", Pending, fmt.Sprintf("batch passed on %s and is waiting for the pull request to satisfy its merge conditions", staging))
+		return QueueEntry{}, false, nil
+	}
+
+	reason, changed, err := checkBatchMembers(ctx, client, config, key, number, batch)
+	if err != nil {
+		return QueueEntry{}, false, err
+	}
+	if changed {
+		queue.cancelBatch(key, batch.id)
+		decision.add("merge queue", Pending, fmt.Sprintf("%s, the batch is being rebuilt", reason))
+		next, ok := queue.Head(key)
+		return next, ok, nil
+	}
+	if reason != "" {
+		decision.add("merge queue", Pending, reason)
+		return QueueEntry{}, false, nil
+	}
+
+	return fastForwardBatch(ctx, queue, client, key, number, batch, decision)
+}
+
+// checkBatchMembers verifies that the pull requests of a batch that passed
+// are still open at the heads that were tested and that the other pull
+// requests still satisfy their merge conditions. It returns the reason the
+// batch cannot be merged yet, if any, and whether the batch must be rebuilt
+// because a pull request changed.
+func checkBatchMembers(ctx context.Context, client *github.Client, config MergeConfig, key QueueKey, number int, batch *Batch) (string, bool, error) {
+	for i, n := range batch.Numbers {
+		pr, _, err := client.PullRequests.Get(ctx, key.Owner, key.Repo, n)
+		if err != nil {
+			return "", false, errors.Wrapf(err, "failed to get pull request %s/%s#%d", key.Owner, key.Repo, n)
+		}
+		if pr.GetState() != "open" || pr.GetMerged() {
+			return fmt.Sprintf("pull request #%d was closed while the batch was tested", n), true, nil
+		}
+		if i >= len(batch.heads) || pr.GetHead().GetSHA() != batch.heads[i] {
+			return fmt.Sprintf("pull request #%d changed while the batch was tested", n), true, nil
+		}
+		if n == number {
+			continue
+		}
+
+		memberDecision, err := evaluateMerge(ctx, pull.NewGithubContext(client, pr), config)
+		if err != nil {
+			return "", false, errors.Wrapf(err, "failed to evaluate pull request #%d", n)
+		}
+		if memberDecision.Result() != Pass {
+			return fmt.Sprintf("batch passed on %s and is waiting for pull request #%d to satisfy its merge conditions", StagingBranch(key.Base), n), false, nil
+		}
+	}
+	return "", false, nil
+}
+
+// buildBatch resets the staging branch to the target branch and merges the
+// heads of the pull requests of the batch into it. Pull requests that
+// conflict are rejected.
+func buildBatch(ctx context.Context, queue *MergeQueue, client *github.Client, key QueueKey, batch *Batch) error {
+	staging := StagingBranch(key.Base)
+
+	base, _, err := client.Git.GetRef(ctx, key.Owner, key.Repo, "heads/"+key.Base)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get ref of %s", key.Base)
+	}
+	baseSHA := base.GetObject().GetSHA()
+
+	if err := resetStagingBranch(ctx, client, key, baseSHA); err != nil {
+		return err
+	}
+
+	sha := baseSHA
+	var numbers []int
+	var heads []string
+	conflicts := make(map[int]string)
+	for _, number := range batch.Numbers {
+		pr, _, err := client.PullRequests.Get(ctx, key.Owner, key.Repo, number)
+		if err != nil {
+			return errors.Wrapf(err, "failed to get pull request %s/%s#%d", key.Owner, key.Repo, number)
+		}
+		head := pr.GetHead().GetSHA()
+
+		commit, _, err := client.Repositories.Merge(ctx, key.Owner, key.Repo, &github.RepositoryMergeRequest{
+			Base:          github.String(staging),
+			Head:          github.String(head),
+			CommitMessage: github.String(fmt.Sprintf("Merge pull request #%d from %s", number, pr.GetHead().GetLabel())),
+		})
+		if err != nil {
+			if rerr, ok := err.(*github.ErrorResponse); ok && rerr.Response != nil && rerr.Response.StatusCode == http.StatusConflict {
+				conflicts[number] = head
+				continue
+			}
+			return errors.Wrapf(err, "failed to merge pull request #%d into %s", number, staging)
+		}
+
+		// an empty response means the head is already part of the branch
+		if commit.GetSHA() != "" {
+			sha = commit.GetSHA()
+		}
+		numbers = append(numbers, number)
+		heads = append(heads, head)
+	}
+
+	if len(numbers) == 0 {
+		queue.cancelBatch(key, batch.id)
+	} else {
+		queue.stageBatch(key, batch.id, numbers, heads, baseSHA, sha)
+	}
+
+	// rejecting a pull request in the batch discards the batch, so
+	// conflicting pull requests are rejected once they are no longer part of it
+	for number, head := range conflicts {
+		queue.Reject(key, number, head, fmt.Sprintf("pull request conflicts with %s or with the pull requests ahead of it in the merge queue", key.Base))
+	}
+	return nil
+}
+
+// fastForwardBatch moves the target branch to the staging commit of a batch
+// that passed, which merges all of its pull requests at once with the merge
+// commits of the staging branch, regardless of the merge method. The merged
+// batch is recorded in the decision for the cleanup after merge. If the target
+// branch cannot be fast-forwarded, for example because branch protection
+// does not allow bulldozer to push, the pull requests are merged one at a
+// time instead.
+func fastForwardBatch(ctx context.Context, queue *MergeQueue, client *github.Client, key QueueKey, number int, batch *Batch, decision *Decision) (QueueEntry, bool, error) {
+	logger := zerolog.Ctx(ctx)
+
+	base, _, err := client.Git.GetRef(ctx, key.Owner, key.Repo, "heads/"+key.Base)
+	if err != nil {
+		return QueueEntry{}, false, errors.Wrapf(err, "failed to get ref of %s", key.Base)
+	}
+	if base.GetObject().GetSHA() != batch.BaseSHA {
+		queue.cancelBatch(key, batch.id)
+		decision.add("merge queue", Pending, fmt.Sprintf("%s changed while the batch was tested, the batch is being rebuilt", key.Base))
+		next, ok := queue.Head(key)
+		return next, ok, nil
+	}
+
+	ref := &github.Reference{
+		Ref:    github.String("heads/" + key.Base),
+		Object: &github.GitObject{SHA: github.String(batch.SHA)},
+	}
+	if _, _, err := client.Git.UpdateRef(ctx, key.Owner, key.Repo, ref, false); err != nil {
+		logger.Info().Err(err).Msgf("Failed to fast-forward %s to %s, merging the batch one pull request at a time", key.Base, batch.SHA)
+		queue.passBatch(key, batch.id)
+		mergeBatchMember(queue, key, number, batch, decision)
+		return QueueEntry{}, false, nil
+	}
+
+	logger.Info().Msgf("Fast-forwarded %s to %s, merging pull requests %v", key.Base, batch.SHA, batch.Numbers)
+
+	next, advance := queue.completeBatch(key, batch.id)
+	if !advance {
+		deleteStagingBranch(ctx, client, key)
+	}
+	// the pull requests are merged already and must not go through the
+	// normal merge, which would also use the merge method and message
+	decision.add("merge queue", Pending, fmt.Sprintf("pull request was merged by fast-forwarding %s to the tested batch", key.Base))
+	decision.MergedBatch = &MergedBatch{
+		Numbers: batch.Numbers,
+		SHA:     batch.SHA,
+	}
+	return next, advance, nil
+}
+
+// mergeBatchMember lets the pull requests of a passed batch merge in the
+// order of the queue.
+func mergeBatchMember(queue *MergeQueue, key QueueKey, number int, batch *Batch, decision *Decision) {
+	if position, _ := queue.Position(key, number); position > 0 {
+		decision.add("merge queue", Pending, "pull request passed the merge queue and is waiting for the pull requests ahead of it to merge")
+		return
+	}
+	decision.add("merge queue", Pass, fmt.Sprintf("pull request passed the merge queue in a batch of %d", len(batch.Numbers)))
+}
+
+func resetStagingBranch(ctx context.Context, client *github.Client, key QueueKey, sha string) error {
+	staging := StagingBranch(key.Base)
+	ref := &github.Reference{
+		Ref:    github.String("heads/" + staging),
+		Object: &github.GitObject{SHA: github.String(sha)},
+	}
+
+	_, _, err := client.Git.UpdateRef(ctx, key.Owner, key.Repo, ref, true)
+	if err == nil {
+		return nil
+	}
+	if rerr, ok := err.(*github.ErrorResponse); !ok || rerr.Response == nil || rerr.Response.StatusCode != http.StatusUnprocessableEntity {
+		return errors.Wrapf(err, "failed to reset %s", staging)
+	}
+
+	// the branch does not exist yet
+	if _, _, err := client.Git.CreateRef(ctx, key.Owner, key.Repo, ref); err != nil {
+		return errors.Wrapf(err, "failed to create %s", staging)
+	}
+	return nil
+}
+
+func deleteStagingBranch(ctx context.Context, client *github.Client, key QueueKey) {
+	logger := zerolog.Ctx(ctx)
+
+	staging := StagingBranch(key.Base)
+	if _, err := client.Git.DeleteRef(ctx, key.Owner, key.Repo, "heads/"+staging); err != nil {
+		logger.Debug().Err(err).Msgf("Failed to delete %s", staging)
+	}
+}
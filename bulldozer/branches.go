@@ -0,0 +1,250 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// MergeOverride replaces parts of a MergeConfig for pull requests targeting
+// matching branches. Fields that are not set keep the value of the
+// MergeConfig.
+type MergeOverride struct {
+	Whitelist *Signals `yaml:"whitelist"`
+	Blacklist *Signals `yaml:"blacklist"`
+
+	DeleteAfterMerge *bool `yaml:"delete_after_merge"`
+
+	Method  MergeMethod  `yaml:"method"`
+	Options MergeOptions `yaml:"options"`
+
+	RequiredStatuses []RequiredStatus `yaml:"required_statuses"`
+}
+
+// UpdateOverride replaces parts of an UpdateConfig for pull requests
+// targeting matching branches. Fields that are not set keep the value of the
+// UpdateConfig.
+type UpdateOverride struct {
+	Whitelist *Signals `yaml:"whitelist"`
+	Blacklist *Signals `yaml:"blacklist"`
+
+	RequiredStatuses []RequiredStatus `yaml:"required_statuses"`
+}
+
+// ForBranch returns a copy of the configuration with the branch overrides
+// for pull requests targeting branch applied.
+func (c *Config) ForBranch(branch string) (*Config, error) {
+	merge, err := c.Merge.forBranch(branch)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid merge.branches")
+	}
+	update, err := c.Update.forBranch(branch)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid update.branches")
+	}
+
+	resolved := *c
+	resolved.Merge = merge
+	resolved.Update = update
+	return &resolved, nil
+}
+
+func (c MergeConfig) forBranch(branch string) (MergeConfig, error) {
+	patterns := make([]string, 0, len(c.Branches))
+	for pattern := range c.Branches {
+		patterns = append(patterns, pattern)
+	}
+
+	pattern, ok, err := matchBranchPattern(patterns, branch)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	o := c.Branches[pattern]
+	if o.Whitelist != nil {
+		c.Whitelist = *o.Whitelist
+	}
+	if o.Blacklist != nil {
+		c.Blacklist = *o.Blacklist
+	}
+	if o.DeleteAfterMerge != nil {
+		c.DeleteAfterMerge = *o.DeleteAfterMerge
+	}
+	if o.Method != "" {
+		c.Method = o.Method
+	}
+	if o.Options.Squash != nil {
+		c.Options.Squash = o.Options.Squash
+	}
+	if o.RequiredStatuses != nil {
+		c.RequiredStatuses = o.RequiredStatuses
+	}
+	return c, nil
+}
+
+func (c UpdateConfig) forBranch(branch string) (UpdateConfig, error) {
+	patterns := make([]string, 0, len(c.Branches))
+	for pattern := range c.Branches {
+		patterns = append(patterns, pattern)
+	}
+
+	pattern, ok, err := matchBranchPattern(patterns, branch)
+	if err != nil || !ok {
+		return c, err
+	}
+
+	o := c.Branches[pattern]
+	if o.Whitelist != nil {
+		c.Whitelist = *o.Whitelist
+	}
+	if o.Blacklist != nil {
+		c.Blacklist = *o.Blacklist
+	}
+	if o.RequiredStatuses != nil {
+		c.RequiredStatuses = o.RequiredStatuses
+	}
+	return c, nil
+}
+
+// matchBranchPattern returns the most specific of the glob patterns that
+// matches branch. A pattern without wildcards is more specific than any glob,
+// and otherwise the pattern with the longest literal prefix wins. Remaining
+// ties are broken by the number of literal characters, then lexically, so
+// the result does not depend on the order of the patterns.
+func matchBranchPattern(patterns []string, branch string) (string, bool, error) {
+	var matches []string
+	for _, pattern := range patterns {
+		rx, err := compileGlob(pattern)
+		if err != nil {
+			return "", false, err
+		}
+		if rx.MatchString(branch) {
+			matches = append(matches, pattern)
+		}
+	}
+	if len(matches) == 0 {
+		return "", false, nil
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		a, b := branchPatternSpecificity(matches[i]), branchPatternSpecificity(matches[j])
+		if a != b {
+			return a.moreSpecificThan(b)
+		}
+		return matches[i] < matches[j]
+	})
+	return matches[0], true, nil
+}
+
+type specificity struct {
+	exact    bool
+	prefix   int
+	literals int
+}
+
+func branchPatternSpecificity(pattern string) specificity {
+	prefix := strings.IndexAny(pattern, "*?[")
+	if prefix < 0 {
+		return specificity{exact: true, prefix: len(pattern), literals: len(pattern)}
+	}
+
+	literals := 0
+	inClass := false
+	for _, c := range pattern {
+		switch {
+		case c == '[':
+			inClass = true
+		case c == ']' && inClass:
+			inClass = false
+		case !inClass && c != '*' && c != '?':
+			literals++
+		}
+	}
+	return specificity{prefix: prefix, literals: literals}
+}
+
+func (s specificity) moreSpecificThan(other specificity) bool {
+	if s.exact != other.exact {
+		return s.exact
+	}
+	if s.prefix != other.prefix {
+		return s.prefix > other.prefix
+	}
+	return s.literals > other.literals
+}
+
+func validateMergeOverrides(overrides map[string]MergeOverride) error {
+	patterns := make([]string, 0, len(overrides))
+	for pattern := range overrides {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		o := overrides[pattern]
+		if _, err := compileGlob(pattern); err != nil {
+			return err
+		}
+		if err := validateOverrideSignals(o.Whitelist, o.Blacklist); err != nil {
+			return errors.Wrapf(err, "%q", pattern)
+		}
+		if err := validateRequiredStatuses(o.RequiredStatuses); err != nil {
+			return errors.Wrapf(err, "%q: invalid required_statuses", pattern)
+		}
+		if err := validateSquashOptions(o.Options.Squash); err != nil {
+			return errors.Wrapf(err, "%q", pattern)
+		}
+	}
+	return nil
+}
+
+func validateUpdateOverrides(overrides map[string]UpdateOverride) error {
+	patterns := make([]string, 0, len(overrides))
+	for pattern := range overrides {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	for _, pattern := range patterns {
+		o := overrides[pattern]
+		if _, err := compileGlob(pattern); err != nil {
+			return err
+		}
+		if err := validateOverrideSignals(o.Whitelist, o.Blacklist); err != nil {
+			return errors.Wrapf(err, "%q", pattern)
+		}
+		if err := validateRequiredStatuses(o.RequiredStatuses); err != nil {
+			return errors.Wrapf(err, "%q: invalid required_statuses", pattern)
+		}
+	}
+	return nil
+}
+
+func validateOverrideSignals(whitelist, blacklist *Signals) error {
+	if whitelist != nil {
+		if err := whitelist.validate(); err != nil {
+			return errors.Wrap(err, "invalid whitelist")
+		}
+	}
+	if blacklist != nil {
+		if err := blacklist.validate(); err != nil {
+			return errors.Wrap(err, "invalid blacklist")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,167 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchBranchPattern(t *testing.T) {
+	patterns := []string{"**", "release/*", "release/2026-*", "release/2026-10", "hotfix/**", "v?"}
+
+	tests := map[string]struct {
+		Branch  string
+		Pattern string
+	}{
+		"exactWins": {
+			Branch:  "release/2026-10",
+			Pattern: "release/2026-10",
+		},
+		"longerPrefixWins": {
+			Branch:  "release/2026-11",
+			Pattern: "release/2026-*",
+		},
+		"glob": {
+			Branch:  "release/legacy",
+			Pattern: "release/*",
+		},
+		"doubleStar": {
+			Branch:  "hotfix/a/b",
+			Pattern: "hotfix/**",
+		},
+		"fallback": {
+			Branch:  "main",
+			Pattern: "**",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pattern, ok, err := matchBranchPattern(patterns, test.Branch)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.Equal(t, test.Pattern, pattern)
+		})
+	}
+
+	t.Run("noMatch", func(t *testing.T) {
+		_, ok, err := matchBranchPattern([]string{"release/*"}, "main")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("tiesAreStable", func(t *testing.T) {
+		for i := 0; i < 10; i++ {
+			pattern, _, err := matchBranchPattern([]string{"r*/a", "re*"}, "rel/a")
+			require.NoError(t, err)
+			assert.Equal(t, "r*/a", pattern)
+		}
+	})
+}
+
+func TestConfigForBranch(t *testing.T) {
+	var cf ConfigFetcher
+	config, err := cf.unmarshalConfig([]byte(`
+version: 1
+merge:
+  whitelist:
+    labels: ["merge when ready"]
+  method: squash
+  delete_after_merge: true
+  required_statuses: ["ci/unit"]
+  branches:
+    "release/*":
+      method: merge
+      delete_after_merge: false
+      required_statuses: ["ci/unit", "ci/integration"]
+    "release/legacy":
+      whitelist:
+        labels: ["legacy"]
+    "docs/**":
+      required_statuses: []
+update:
+  required_statuses: ["ci/unit"]
+  branches:
+    "release/*":
+      blacklist:
+        labels: ["frozen"]
+`))
+	require.NoError(t, err)
+
+	t.Run("noOverride", func(t *testing.T) {
+		resolved, err := config.ForBranch("main")
+		require.NoError(t, err)
+		assert.Equal(t, SquashAndMerge, resolved.Merge.Method)
+		assert.True(t, resolved.Merge.DeleteAfterMerge)
+		assert.Equal(t, requiredStatusNames([]string{"ci/unit"}), resolved.Merge.RequiredStatuses)
+		assert.False(t, resolved.Update.Blacklist.Enabled())
+	})
+
+	t.Run("override", func(t *testing.T) {
+		resolved, err := config.ForBranch("release/1.2")
+		require.NoError(t, err)
+		assert.Equal(t, MergeCommit, resolved.Merge.Method)
+		assert.False(t, resolved.Merge.DeleteAfterMerge)
+		assert.Equal(t, requiredStatusNames([]string{"ci/unit", "ci/integration"}), resolved.Merge.RequiredStatuses)
+		assert.Equal(t, []string{"merge when ready"}, resolved.Merge.Whitelist.Labels)
+		assert.Equal(t, []string{"frozen"}, resolved.Update.Blacklist.Labels)
+		assert.Equal(t, requiredStatusNames([]string{"ci/unit"}), resolved.Update.RequiredStatuses)
+
+		// the original configuration is unchanged
+		assert.Equal(t, SquashAndMerge, config.Merge.Method)
+		assert.False(t, config.Update.Blacklist.Enabled())
+	})
+
+	t.Run("mostSpecificOnly", func(t *testing.T) {
+		resolved, err := config.ForBranch("release/legacy")
+		require.NoError(t, err)
+		assert.Equal(t, SquashAndMerge, resolved.Merge.Method)
+		assert.Equal(t, []string{"legacy"}, resolved.Merge.Whitelist.Labels)
+		assert.Equal(t, requiredStatusNames([]string{"ci/unit"}), resolved.Merge.RequiredStatuses)
+	})
+
+	t.Run("emptyOverride", func(t *testing.T) {
+		resolved, err := config.ForBranch("docs/guide")
+		require.NoError(t, err)
+		assert.Empty(t, resolved.Merge.RequiredStatuses)
+	})
+}
+
+func TestValidateBranchOverrides(t *testing.T) {
+	var cf ConfigFetcher
+
+	_, err := cf.unmarshalConfig([]byte(`
+version: 1
+merge:
+  branches:
+    "release/[":
+      method: merge
+`))
+	assert.EqualError(t, err, `invalid merge.branches: invalid glob "release/[": unterminated character class`)
+
+	_, err = cf.unmarshalConfig([]byte(`
+version: 1
+update:
+  branches:
+    "release/*":
+      required_statuses:
+        - pattern: "ci/*"
+          regex: "^ci/"
+`))
+	assert.Error(t, err)
+}
@@ -0,0 +1,223 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+// CodeOwnersPaths are the locations of the CODEOWNERS file, in the order
+// GitHub looks for them.
+var CodeOwnersPaths = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// maxListedPaths limits the number of paths listed in review reasons
+const maxListedPaths = 5
+
+// codeOwnersLoader loads the CODEOWNERS file of a repository on demand.
+type codeOwnersLoader func(ctx context.Context) (*CodeOwners, error)
+
+// codeOwners returns the CODEOWNERS file of the repository, loading it if
+// necessary. It returns nil if the repository has none.
+func (c *MergeConfig) codeOwners(ctx context.Context) (*CodeOwners, error) {
+	if c.CodeOwners != nil || c.loadCodeOwners == nil {
+		return c.CodeOwners, nil
+	}
+	return c.loadCodeOwners(ctx)
+}
+
+// CodeOwners is a parsed CODEOWNERS file.
+type CodeOwners struct {
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern string
+	rx      *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeOwners parses the content of a CODEOWNERS file. Like GitHub, it
+// skips lines with invalid patterns. Owners identified by email are kept, but
+// cannot be matched to reviewers, so paths owned only by email addresses
+// cannot be approved.
+func ParseCodeOwners(content []byte) *CodeOwners {
+	var co CodeOwners
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		rx, err := compileCodeOwnersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+
+		// rules without owners unset the owners of matching paths
+		var owners []string
+		owners = append(owners, fields[1:]...)
+
+		co.rules = append(co.rules, codeOwnersRule{
+			pattern: fields[0],
+			rx:      rx,
+			owners:  owners,
+		})
+	}
+
+	return &co
+}
+
+// compileCodeOwnersPattern converts a CODEOWNERS pattern, which follows the
+// gitignore rules, to a regular expression.
+func compileCodeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	glob := pattern
+
+	// patterns with a leading or inner slash are relative to the root,
+	// others match at any depth
+	trimmed := strings.TrimSuffix(glob, "/")
+	if strings.HasPrefix(trimmed, "/") {
+		glob = strings.TrimPrefix(glob, "/")
+	} else if !strings.Contains(trimmed, "/") {
+		glob = "**/" + glob
+	}
+
+	// directories and names without wildcards match everything inside
+	// matching directories, while wildcards in the last segment only match
+	// direct children, so "docs/*" does not match "docs/a/b.md"
+	directory := strings.HasSuffix(glob, "/")
+	glob = strings.TrimSuffix(glob, "/")
+	last := glob[strings.LastIndex(glob, "/")+1:]
+	if (directory || !strings.ContainsAny(last, "*?[")) && !strings.HasSuffix(glob, "/**") {
+		glob += "/**"
+	}
+
+	return compileGlob(glob)
+}
+
+// Owners returns the owners of the path. As in GitHub, the last matching rule
+// takes precedence. A nil result means the path has no owners.
+func (co *CodeOwners) Owners(path string) []string {
+	for i := len(co.rules) - 1; i >= 0; i-- {
+		if co.rules[i].rx.MatchString(path) {
+			return co.rules[i].owners
+		}
+	}
+	return nil
+}
+
+// HasCodeOwnerApproval returns true if every changed file of the PR that has
+// code owners is approved by at least one of its owners, false otherwise. Only
+// approvals from users with write access count. Additionally, a description of
+// the reason will be returned.
+func HasCodeOwnerApproval(ctx context.Context, pullCtx pull.Context, codeOwners *CodeOwners, requirements RequiredReviews) (bool, string, error) {
+	if codeOwners == nil {
+		return true, "repository has no CODEOWNERS file", nil
+	}
+
+	files, err := pullCtx.Files(ctx)
+	if err != nil {
+		return false, "unable to list pull request files", err
+	}
+
+	reviews, err := pullCtx.Reviews(ctx)
+	if err != nil {
+		return false, "unable to list pull request reviews", err
+	}
+	approvers, err := withWriteAccess(ctx, pullCtx, latestReviewStates(reviews, pullCtx.HeadSHA(), requirements.DismissStaleApprovalsOnPush).approved)
+	if err != nil {
+		return false, "unable to determine permissions of reviewers", err
+	}
+
+	// cache results by owner, many files usually share the same owners
+	approvedBy := make(map[string]bool)
+	isApprovedBy := func(owner string) (bool, error) {
+		if approved, ok := approvedBy[owner]; ok {
+			return approved, nil
+		}
+
+		// owners identified by email cannot be matched to reviewers
+		if !strings.HasPrefix(owner, "@") {
+			approvedBy[owner] = false
+			return false, nil
+		}
+
+		approved := false
+		name := strings.TrimPrefix(owner, "@")
+		for _, approver := range approvers {
+			if strings.Contains(name, "/") {
+				member, err := pullCtx.IsTeamMember(ctx, name, approver)
+				if err != nil {
+					return false, err
+				}
+				approved = member
+			} else {
+				approved = strings.EqualFold(name, approver)
+			}
+			if approved {
+				break
+			}
+		}
+
+		approvedBy[owner] = approved
+		return approved, nil
+	}
+
+	var unapproved []string
+	for _, file := range files {
+		owners := codeOwners.Owners(file)
+		if len(owners) == 0 {
+			continue
+		}
+
+		approved := false
+		for _, owner := range owners {
+			ok, err := isApprovedBy(owner)
+			if err != nil {
+				return false, "unable to determine team membership of reviewers", err
+			}
+			if ok {
+				approved = true
+				break
+			}
+		}
+
+		if !approved {
+			unapproved = append(unapproved, fmt.Sprintf("%s (%s)", file, strings.Join(owners, ", ")))
+		}
+	}
+
+	if len(unapproved) > 0 {
+		listed := unapproved
+		if len(listed) > maxListedPaths {
+			listed = append(listed[:maxListedPaths:maxListedPaths], fmt.Sprintf("and %d more", len(unapproved)-maxListedPaths))
+		}
+		return false, fmt.Sprintf("pull request is missing approvals from code owners of %s", strings.Join(listed, ", ")), nil
+	}
+
+	return true, "all changed files with code owners are approved by an owner", nil
+}
@@ -0,0 +1,174 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull"
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+const testCodeOwners = `
+# default owners
+*                       @org/core
+
+*.js                    @frontend-lead # javascript
+/build/                 @org/release
+docs/                   @writer docs@example.com
+/scripts/*              @org/tools
+/apps/**/migrations     @org/dba
+/legal/                 legal@example.com
+unowned.txt
+[invalid
+`
+
+func TestCodeOwnersOwners(t *testing.T) {
+	co := ParseCodeOwners([]byte(testCodeOwners))
+
+	tests := map[string][]string{
+		"main.go":                       {"@org/core"},
+		"web/app.js":                    {"@frontend-lead"},
+		"build/Dockerfile":              {"@org/release"},
+		"tools/build/script.sh":         {"@org/core"},
+		"docs/index.md":                 {"@writer", "docs@example.com"},
+		"guides/docs/index.md":          {"@writer", "docs@example.com"},
+		"legal/LICENSE":                 {"legal@example.com"},
+		"apps/billing/migrations/1.sql": {"@org/dba"},
+		"apps/migrations/1.sql":         {"@org/dba"},
+		"scripts/release.sh":            {"@org/tools"},
+		"scripts/ci/test.sh":            {"@org/core"},
+		"unowned.txt":                   nil,
+		"sub/unowned.txt":               nil,
+	}
+
+	for path, owners := range tests {
+		assert.Equal(t, owners, co.Owners(path), "incorrect owners for %s", path)
+	}
+}
+
+func TestHasCodeOwnerApproval(t *testing.T) {
+	ctx := context.Background()
+	co := ParseCodeOwners([]byte(testCodeOwners))
+
+	tests := map[string]struct {
+		Files    []string
+		Reviews  []*pull.Review
+		Approved bool
+		Reason   string
+	}{
+		"approvedByUser": {
+			Files: []string{"web/app.js", "unowned.txt"},
+			Reviews: []*pull.Review{
+				{Author: "Frontend-Lead", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Approved: true,
+			Reason:   "all changed files with code owners are approved by an owner",
+		},
+		"approvedByTeamMember": {
+			Files: []string{"main.go", "build/Dockerfile"},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "bob", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Approved: true,
+			Reason:   "all changed files with code owners are approved by an owner",
+		},
+		"missingOwnerApproval": {
+			Files: []string{"main.go", "apps/billing/migrations/1.sql", "docs/index.md"},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "writer", State: pull.ReviewCommented, CommitSHA: "head"},
+			},
+			Approved: false,
+			Reason:   "pull request is missing approvals from code owners of apps/billing/migrations/1.sql (@org/dba), docs/index.md (@writer, docs@example.com)",
+		},
+		"approvedByOwnerNextToEmail": {
+			Files: []string{"docs/index.md"},
+			Reviews: []*pull.Review{
+				{Author: "writer", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Approved: true,
+			Reason:   "all changed files with code owners are approved by an owner",
+		},
+		"ownedOnlyByEmail": {
+			Files: []string{"legal/LICENSE"},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Approved: false,
+			Reason:   "pull request is missing approvals from code owners of legal/LICENSE (legal@example.com)",
+		},
+		"teamMemberWithoutWriteAccess": {
+			Files: []string{"main.go"},
+			Reviews: []*pull.Review{
+				{Author: "mallory", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Approved: false,
+			Reason:   "pull request is missing approvals from code owners of main.go (@org/core)",
+		},
+		"changesRequestedIsNotApproval": {
+			Files: []string{"web/app.js"},
+			Reviews: []*pull.Review{
+				{Author: "frontend-lead", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "frontend-lead", State: pull.ReviewChangesRequested, CommitSHA: "head"},
+			},
+			Approved: false,
+			Reason:   "pull request is missing approvals from code owners of web/app.js (@frontend-lead)",
+		},
+		"manyMissingApprovals": {
+			Files:    []string{"a.go", "b.go", "c.go", "d.go", "e.go", "f.go", "g.go"},
+			Approved: false,
+			Reason:   "pull request is missing approvals from code owners of a.go (@org/core), b.go (@org/core), c.go (@org/core), d.go (@org/core), e.go (@org/core), and 2 more",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{
+				HeadSHAValue: "head",
+				FilesValue:   test.Files,
+				ReviewsValue: test.Reviews,
+				TeamMembersValue: map[string][]string{
+					"org/core":    {"alice", "mallory"},
+					"org/release": {"bob"},
+				},
+				PermissionValue: map[string]string{
+					"alice":         "write",
+					"bob":           "admin",
+					"Frontend-Lead": "write",
+					"frontend-lead": "write",
+					"writer":        "write",
+					"mallory":       "read",
+				},
+			}
+
+			approved, reason, err := HasCodeOwnerApproval(ctx, pc, co, RequiredReviews{})
+			require.NoError(t, err)
+			assert.Equal(t, test.Approved, approved)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+
+	t.Run("noCodeOwners", func(t *testing.T) {
+		approved, _, err := HasCodeOwnerApproval(ctx, &pulltest.MockPullContext{}, nil, RequiredReviews{})
+		require.NoError(t, err)
+		assert.True(t, approved)
+	})
+}
@@ -80,22 +80,75 @@ func (cf *ConfigFetcher) ConfigForPR(ctx context.Context, client *github.Client,
 	if err == nil && bytes != nil {
 		if config, err := cf.unmarshalConfig(bytes); err == nil {
 			logger.Debug().Msgf("Found v1 configuration at %s", cf.configurationV1Path)
-			fc.Config = config
-			return fc, nil
+			return cf.withBranchOverrides(ctx, client, fc, config)
 		}
 	}
 	logger.Debug().Err(err).Msgf("v1 configuration was missing or invalid, falling back to server configuration")
 
 	if cf.defaultRepositoryConfig != nil {
 		logger.Debug().Msgf("No repository configuration found, using server-provided default")
-		fc.Config = cf.defaultRepositoryConfig
-		return fc, nil
+		return cf.withBranchOverrides(ctx, client, fc, cf.defaultRepositoryConfig)
 	}
 
 	fc.Error = errors.New("No configuration found")
 	return fc, nil
 }
 
+// withBranchOverrides resolves the branch overrides of the configuration for
+// the base branch of the pull request before setting it on the FetchedConfig.
+func (cf *ConfigFetcher) withBranchOverrides(ctx context.Context, client *github.Client, fc FetchedConfig, config *Config) (FetchedConfig, error) {
+	resolved, err := config.ForBranch(fc.Ref)
+	if err != nil {
+		fc.Error = err
+		return fc, nil
+	}
+	return cf.withCodeOwners(ctx, client, fc, resolved)
+}
+
+// withCodeOwners sets the configuration on the FetchedConfig, loading the
+// CODEOWNERS file of the repository if the configuration requires code owner
+// reviews. Otherwise, the file is only loaded if the evaluation finds that the
+// branch protection of the target branch requires code owner reviews.
+func (cf *ConfigFetcher) withCodeOwners(ctx context.Context, client *github.Client, fc FetchedConfig, config *Config) (FetchedConfig, error) {
+	// the configuration may be shared, so modify a copy
+	withOwners := *config
+	fc.Config = &withOwners
+
+	if !config.Merge.RequiredReviews.RequireCodeOwnerReview {
+		withOwners.Merge.loadCodeOwners = func(ctx context.Context) (*CodeOwners, error) {
+			return cf.fetchCodeOwners(ctx, client, fc)
+		}
+		return fc, nil
+	}
+
+	codeOwners, err := cf.fetchCodeOwners(ctx, client, fc)
+	if err != nil {
+		return fc, err
+	}
+	withOwners.Merge.CodeOwners = codeOwners
+	return fc, nil
+}
+
+// fetchCodeOwners returns the CODEOWNERS file of the repository, or nil if
+// there is none.
+func (cf *ConfigFetcher) fetchCodeOwners(ctx context.Context, client *github.Client, fc FetchedConfig) (*CodeOwners, error) {
+	logger := zerolog.Ctx(ctx)
+
+	for _, path := range CodeOwnersPaths {
+		bytes, err := cf.fetchConfigContents(ctx, client, fc.Owner, fc.Repo, fc.Ref, path)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to fetch CODEOWNERS")
+		}
+		if bytes != nil {
+			logger.Debug().Msgf("Found CODEOWNERS at %s", path)
+			return ParseCodeOwners(bytes), nil
+		}
+	}
+
+	logger.Debug().Msgf("No CODEOWNERS found")
+	return nil, nil
+}
+
 // fetchConfigContents returns a nil slice if there is no configuration file
 func (cf *ConfigFetcher) fetchConfigContents(ctx context.Context, client *github.Client, owner, repo, ref, configPath string) ([]byte, error) {
 	logger := zerolog.Ctx(ctx)
@@ -136,26 +189,79 @@ func (cf *ConfigFetcher) unmarshalConfig(bytes []byte) (*Config, error) {
 		return nil, errors.Errorf("unexpected version '%d', expected 1", config.Version)
 	}
 
-	if config.Merge.Options.Squash != nil {
-		s := config.Merge.Options.Squash
-		delim := 0
-
-		if s.MessageEndMarkerRx != "" {
-			if _, err := regexp.Compile(s.MessageEndMarkerRx); err != nil {
-				return nil, errors.Errorf("invalid syntax of message_end_marker_rx: %v", err)
-			}
-			delim++
-		}
-		if s.MessageEndMarker != "" {
-			delim++
-		}
-		if s.MessageDelimiter != "" {
-			delim++
-		}
-		if delim > 1 {
-			return nil, errors.New("only one of message_end_marker_rx, message_end_marker, message_delimiter_rx, message_delimiter can be set")
+	for _, signals := range []struct {
+		key     string
+		signals *Signals
+	}{
+		{"merge.whitelist", &config.Merge.Whitelist},
+		{"merge.blacklist", &config.Merge.Blacklist},
+		{"update.whitelist", &config.Update.Whitelist},
+		{"update.blacklist", &config.Update.Blacklist},
+	} {
+		if err := signals.signals.validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", signals.key)
 		}
 	}
 
+	if err := validateRequiredStatuses(config.Merge.RequiredStatuses); err != nil {
+		return nil, errors.Wrap(err, "invalid merge.required_statuses")
+	}
+	if err := validateRequiredStatuses(config.Update.RequiredStatuses); err != nil {
+		return nil, errors.Wrap(err, "invalid update.required_statuses")
+	}
+
+	if err := config.Merge.StatusConclusions.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid merge.status_conclusions")
+	}
+	if err := config.Update.StatusConclusions.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid update.status_conclusions")
+	}
+
+	if err := config.Merge.Schedule.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid merge.schedule")
+	}
+	if err := config.Update.Schedule.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid update.schedule")
+	}
+
+	if err := config.Merge.Queue.validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid merge.queue")
+	}
+
+	if err := validateSquashOptions(config.Merge.Options.Squash); err != nil {
+		return nil, err
+	}
+
+	if err := validateMergeOverrides(config.Merge.Branches); err != nil {
+		return nil, errors.Wrap(err, "invalid merge.branches")
+	}
+	if err := validateUpdateOverrides(config.Update.Branches); err != nil {
+		return nil, errors.Wrap(err, "invalid update.branches")
+	}
+
 	return &config, nil
 }
+
+func validateSquashOptions(s *SquashOptions) error {
+	if s == nil {
+		return nil
+	}
+
+	delim := 0
+	if s.MessageEndMarkerRx != "" {
+		if _, err := regexp.Compile(s.MessageEndMarkerRx); err != nil {
+			return errors.Errorf("invalid syntax of message_end_marker_rx: %v", err)
+		}
+		delim++
+	}
+	if s.MessageEndMarker != "" {
+		delim++
+	}
+	if s.MessageDelimiter != "" {
+		delim++
+	}
+	if delim > 1 {
+		return errors.New("only one of message_end_marker_rx, message_end_marker, message_delimiter_rx, message_delimiter can be set")
+	}
+	return nil
+}
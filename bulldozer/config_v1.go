@@ -44,9 +44,52 @@ type MergeConfig struct {
 
 	BranchMethod map[string]MergeMethod `yaml:"branch_method"`
 
+	// Branches overrides parts of the configuration for pull requests
+	// targeting branches that match the keys, which are globs. If several
+	// keys match, the most specific one applies.
+	Branches map[string]MergeOverride `yaml:"branches"`
+
 	// Additional status checks that bulldozer should require
 	// (even if the branch protection settings doesn't require it)
-	RequiredStatuses []string `yaml:"required_statuses"`
+	RequiredStatuses []RequiredStatus `yaml:"required_statuses"`
+
+	// StatusConclusions overrides how the conclusions of required status
+	// checks are treated
+	StatusConclusions StatusConclusions `yaml:"status_conclusions"`
+
+	SizeLimits SizeLimits `yaml:"size_limits"`
+
+	RequiredReviews RequiredReviews `yaml:"required_reviews"`
+
+	Schedule Schedule `yaml:"schedule"`
+
+	Queue QueueConfig `yaml:"queue"`
+
+	// AutoMerge enables GitHub's auto-merge on pull requests that pass all
+	// conditions instead of merging them, and disables it when a condition
+	// fails.
+	AutoMerge bool `yaml:"auto_merge"`
+
+	// CodeOwners is loaded from the repository by the ConfigFetcher when
+	// code owner reviews are required
+	CodeOwners *CodeOwners `yaml:"-"`
+
+	// loadCodeOwners loads the CODEOWNERS file if CodeOwners was not loaded
+	// because only branch protection requires code owner reviews
+	loadCodeOwners codeOwnersLoader
+
+	// ReportCheckRun is the check run bulldozer reports with, which is set by
+	// the server and never counts as a required status
+	ReportCheckRun CheckRunID `yaml:"-"`
+}
+
+// SizeLimits are upper bounds on the size of pull requests. Zero values
+// disable the corresponding limit.
+type SizeLimits struct {
+	MaxAdditions    int `yaml:"max_additions"`
+	MaxDeletions    int `yaml:"max_deletions"`
+	MaxChangedFiles int `yaml:"max_changed_files"`
+	MaxCommits      int `yaml:"max_commits"`
 }
 
 type MergeOptions struct {
@@ -61,15 +104,47 @@ type SquashOptions struct {
 	MessageDelimiter   string          `yaml:"message_delimiter"`
 }
 
+type RequiredReviews struct {
+	// MinApprovals is the number of distinct users that must approve the
+	// pull request.
+	MinApprovals int `yaml:"min_approvals"`
+
+	// BlockOnChangesRequested prevents merging while any reviewer's latest
+	// review requests changes.
+	BlockOnChangesRequested bool `yaml:"block_on_changes_requested"`
+
+	// DismissStaleApprovalsOnPush ignores approvals given for commits other
+	// than the current head of the pull request.
+	DismissStaleApprovalsOnPush bool `yaml:"dismiss_stale_approvals_on_push"`
+
+	// RequireCodeOwnerReview requires an approval from a code owner of every
+	// changed file, as defined by the CODEOWNERS file of the target branch.
+	RequireCodeOwnerReview bool `yaml:"require_code_owner_review"`
+}
+
 type UpdateConfig struct {
 	Whitelist Signals `yaml:"whitelist"`
 	Blacklist Signals `yaml:"blacklist"`
 
 	// Status checks to require for update
-	RequiredStatuses                     []string            `yaml:"required_statuses"`
+	RequiredStatuses                     []RequiredStatus    `yaml:"required_statuses"`
 	RequiredStatusesDescriptionWhitelist map[string][]string `yaml:"required_statuses_description_whitelist"`
 
+	StatusConclusions StatusConclusions `yaml:"status_conclusions"`
+
 	DraftUpdate bool `yaml:"draft_update"`
+
+	SizeLimits SizeLimits `yaml:"size_limits"`
+
+	Schedule Schedule `yaml:"schedule"`
+
+	// Branches overrides parts of the configuration for pull requests
+	// targeting branches that match the keys, like MergeConfig.Branches.
+	Branches map[string]UpdateOverride `yaml:"branches"`
+
+	// ReportCheckRun is the check run bulldozer reports with, like
+	// MergeConfig.ReportCheckRun.
+	ReportCheckRun CheckRunID `yaml:"-"`
 }
 
 type Config struct {
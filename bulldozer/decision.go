@@ -0,0 +1,112 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"fmt"
+	"strings"
+)
+
+type Result string
+
+const (
+	// Pass means the condition is satisfied.
+	Pass Result = "pass"
+
+	// Fail means the condition is not satisfied and will not be satisfied
+	// without changes to the pull request or its configuration.
+	Fail Result = "fail"
+
+	// Pending means the condition is not satisfied yet, but may be satisfied
+	// later, for example when status checks complete.
+	Pending Result = "pending"
+)
+
+// Condition is the outcome of evaluating a single requirement for merging or
+// updating a pull request.
+type Condition struct {
+	Name   string
+	Result Result
+	Reason string
+}
+
+func (c Condition) String() string {
+	return fmt.Sprintf("%s (%s): %s", c.Name, c.Result, c.Reason)
+}
+
+// Decision records the conditions evaluated to decide whether to merge or
+// update a pull request. Evaluation stops at the first failed condition, so
+// later conditions are missing from decisions that fail.
+type Decision struct {
+	Conditions []Condition
+
+	// MergedBatch is the batch of the merge queue that the pull request was
+	// merged with during the evaluation, if any. The merge queue condition
+	// is pending in this case, so that the pull request is not merged again.
+	MergedBatch *MergedBatch
+}
+
+func (d *Decision) add(name string, result Result, reason string) {
+	d.Conditions = append(d.Conditions, Condition{Name: name, Result: result, Reason: reason})
+}
+
+// Result returns Fail if any condition failed, Pending if any condition is
+// pending, and Pass otherwise.
+func (d *Decision) Result() Result {
+	result := Pass
+	for _, c := range d.Conditions {
+		switch c.Result {
+		case Fail:
+			return Fail
+		case Pending:
+			result = Pending
+		}
+	}
+	return result
+}
+
+// Passed returns true if all conditions passed.
+func (d *Decision) Passed() bool {
+	return d.Result() == Pass
+}
+
+// Blocking returns the conditions that did not pass.
+func (d *Decision) Blocking() []Condition {
+	var blocking []Condition
+	for _, c := range d.Conditions {
+		if c.Result != Pass {
+			blocking = append(blocking, c)
+		}
+	}
+	return blocking
+}
+
+// String returns a summary of the reasons the decision did not pass, or of
+// all conditions if it passed.
+func (d *Decision) String() string {
+	conditions := d.Blocking()
+	if len(conditions) == 0 {
+		conditions = d.Conditions
+	}
+	if len(conditions) == 0 {
+		return "no conditions evaluated"
+	}
+
+	reasons := make([]string, len(conditions))
+	for i, c := range conditions {
+		reasons[i] = c.String()
+	}
+	return strings.Join(reasons, "; ")
+}
@@ -0,0 +1,67 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecision(t *testing.T) {
+	tests := map[string]struct {
+		Conditions []Condition
+		Result     Result
+		String     string
+	}{
+		"empty": {
+			Result: Pass,
+			String: "no conditions evaluated",
+		},
+		"passed": {
+			Conditions: []Condition{
+				{Name: "whitelist", Result: Pass, Reason: "pull request has a whitelist label: \"merge\""},
+				{Name: `status "ci"`, Result: Pass, Reason: "status check succeeded"},
+			},
+			Result: Pass,
+			String: `whitelist (pass): pull request has a whitelist label: "merge"; status "ci" (pass): status check succeeded`,
+		},
+		"pending": {
+			Conditions: []Condition{
+				{Name: "whitelist", Result: Pass, Reason: "pull request has a whitelist label: \"merge\""},
+				{Name: `status "ci"`, Result: Pending, Reason: "status check has not been reported"},
+			},
+			Result: Pending,
+			String: `status "ci" (pending): status check has not been reported`,
+		},
+		"failed": {
+			Conditions: []Condition{
+				{Name: "schedule", Result: Pending, Reason: "the holiday freeze is in effect"},
+				{Name: "size limits", Result: Fail, Reason: "pull request has 30 commits, more than the limit of 20"},
+			},
+			Result: Fail,
+			String: "schedule (pending): the holiday freeze is in effect; size limits (fail): pull request has 30 commits, more than the limit of 20",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			decision := &Decision{Conditions: test.Conditions}
+			assert.Equal(t, test.Result, decision.Result())
+			assert.Equal(t, test.Result == Pass, decision.Passed())
+			assert.Equal(t, test.String, decision.String())
+		})
+	}
+}
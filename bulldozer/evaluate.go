@@ -16,7 +16,6 @@ package bulldozer
 
 import (
 	"context"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -46,73 +45,165 @@ func IsPRWhitelisted(ctx context.Context, pullCtx pull.Context, config Signals)
 	return matches, reason, err
 }
 
-// setDifference returns all elements in set1 that
-// are not in set2.
-func setDifference(set1, set2 []string) []string {
-	m2 := make(map[string]struct{})
-	for _, s2 := range set2 {
-		m2[s2] = struct{}{}
+// ShouldMergePR evaluates the conditions for merging the pull request. The
+// pull request should be merged if the returned decision passed.
+func ShouldMergePR(ctx context.Context, pullCtx pull.Context, mergeConfig MergeConfig) (*Decision, error) {
+	logger := zerolog.Ctx(ctx)
+
+	decision, err := evaluateMerge(ctx, pullCtx, mergeConfig)
+	if err != nil {
+		return nil, err
 	}
 
-	seen := make(map[string]struct{})
-	var result []string
-	for _, s1 := range set1 {
-		if _, ok := m2[s1]; !ok {
-			if _, alreadySeen := seen[s1]; !alreadySeen {
-				result = append(result, s1)
-				seen[s1] = struct{}{}
-			}
-		}
+	if decision.Passed() {
+		logger.Debug().Msgf("%s is deemed mergeable", pullCtx.Locator())
+	} else {
+		logger.Debug().Msgf("%s is deemed not mergeable because %s", pullCtx.Locator(), decision)
 	}
-	return result
+	return decision, nil
 }
 
-// ShouldMergePR TODO: may want to return a richer type than bool
-func ShouldMergePR(ctx context.Context, pullCtx pull.Context, mergeConfig MergeConfig) (bool, error) {
-	logger := zerolog.Ctx(ctx)
+func evaluateMerge(ctx context.Context, pullCtx pull.Context, mergeConfig MergeConfig) (*Decision, error) {
+	decision := &Decision{}
 
 	if mergeConfig.Blacklist.Enabled() {
 		blacklisted, reason, err := IsPRBlacklisted(ctx, pullCtx, mergeConfig.Blacklist)
 		if err != nil {
-			return false, errors.Wrap(err, "failed to determine if pull request is blacklisted")
+			return nil, errors.Wrap(err, "failed to determine if pull request is blacklisted")
 		}
 		if blacklisted {
-			logger.Debug().Msgf("%s is deemed not mergeable because blacklisting is enabled and %s", pullCtx.Locator(), reason)
-			return false, nil
+			decision.add("blacklist", Fail, reason)
+			return decision, nil
 		}
+		decision.add("blacklist", Pass, reason)
 	}
 
 	if mergeConfig.Whitelist.Enabled() {
 		whitelisted, reason, err := IsPRWhitelisted(ctx, pullCtx, mergeConfig.Whitelist)
 		if err != nil {
-			return false, errors.Wrap(err, "failed to determine if pull request is whitelisted")
+			return nil, errors.Wrap(err, "failed to determine if pull request is whitelisted")
 		}
 		if !whitelisted {
-			logger.Debug().Msgf("%s is deemed not mergeable because whitelisting is enabled and no whitelist signal detected", pullCtx.Locator())
-			return false, nil
+			decision.add("whitelist", Fail, reason)
+			return decision, nil
 		}
+		decision.add("whitelist", Pass, reason)
+	}
 
-		logger.Debug().Msgf("%s is whitelisted because whitelisting is enabled and %s", pullCtx.Locator(), reason)
+	if mergeConfig.SizeLimits.Enabled() {
+		tooLarge, reason, err := IsPRTooLarge(ctx, pullCtx, mergeConfig.SizeLimits)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to determine if pull request is too large")
+		}
+		if tooLarge {
+			decision.add("size limits", Fail, reason)
+			return decision, nil
+		}
+		decision.add("size limits", Pass, reason)
+	}
+
+	if mergeConfig.Schedule.Enabled() {
+		scheduled, reason, err := IsPRScheduled(ctx, mergeConfig.Schedule)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to determine if merging is scheduled")
+		}
+		if scheduled {
+			decision.add("schedule", Pass, reason)
+		} else {
+			decision.add("schedule", Pending, reason)
+		}
 	}
 
 	requiredStatuses, err := pullCtx.RequiredStatuses(ctx)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to determine required Github status checks")
+		return nil, errors.Wrap(err, "failed to determine required Github status checks")
 	}
-	requiredStatuses = append(requiredStatuses, mergeConfig.RequiredStatuses...)
+	required := append(requiredStatusNames(requiredStatuses), mergeConfig.RequiredStatuses...)
 
-	successStatuses, _, err := pullCtx.CurrentStatuses(ctx)
+	statuses, err := pullCtx.CurrentStatuses(ctx)
 	if err != nil {
-		return false, errors.Wrap(err, "failed to determine currently successful status checks")
+		return nil, errors.Wrap(err, "failed to determine current status checks")
+	}
+	statuses = withoutCheckRun(statuses, mergeConfig.ReportCheckRun)
+	if err := requiredStatusConditions(decision, required, statuses, mergeConfig.StatusConclusions, nil); err != nil {
+		return nil, err
+	}
+	if decision.Result() == Fail {
+		return decision, nil
+	}
+
+	if mergeConfig.RequiredReviews.Enabled() {
+		reviewed, reason, err := HasRequiredReviews(ctx, pullCtx, mergeConfig.RequiredReviews)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to determine if pull request has required reviews")
+		}
+		if reviewed {
+			decision.add("required reviews", Pass, reason)
+		} else {
+			decision.add("required reviews", Pending, reason)
+		}
 	}
 
-	unsatisfiedStatuses := setDifference(requiredStatuses, successStatuses)
-	if len(unsatisfiedStatuses) > 0 {
-		logger.Debug().Msgf("%s is deemed not mergeable because of unfulfilled status checks: [%s]", pullCtx.Locator(), strings.Join(unsatisfiedStatuses, ","))
-		return false, nil
+	if mergeConfig.RequiredReviews.RequireCodeOwnerReview {
+		approved, reason, err := HasCodeOwnerApproval(ctx, pullCtx, mergeConfig.CodeOwners, mergeConfig.RequiredReviews)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to determine if pull request has code owner approval")
+		}
+		if approved {
+			decision.add("code owner review", Pass, reason)
+		} else {
+			decision.add("code owner review", Pending, reason)
+		}
+	}
+
+	// Check the review requirements of branch protection before trying a
+	// merge, which GitHub would reject. This needs several requests, so it
+	// waits until all other conditions pass.
+	if decision.Result() == Pass {
+		if err := protectionReviewConditions(ctx, pullCtx, mergeConfig, decision); err != nil {
+			return nil, err
+		}
 	}
 
-	// Ignore required reviews and try a merge (which may fail with a 4XX).
+	return decision, nil
+}
+
+// protectionReviewConditions adds conditions for the review requirements of
+// the branch protection of the target branch to the decision.
+func protectionReviewConditions(ctx context.Context, pullCtx pull.Context, mergeConfig MergeConfig, decision *Decision) error {
+	protection, err := pullCtx.ReviewProtection(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to determine branch protection review requirements")
+	}
+	if protection == nil {
+		return nil
+	}
 
-	return true, nil
+	reviewed, reason, err := HasProtectionReviews(ctx, pullCtx, protection)
+	if err != nil {
+		return errors.Wrap(err, "failed to determine if pull request has reviews required by branch protection")
+	}
+	if reviewed {
+		decision.add("branch protection reviews", Pass, reason)
+	} else {
+		decision.add("branch protection reviews", Pending, reason)
+	}
+
+	// the code owner review condition already covers this
+	if protection.RequireCodeOwnerReviews && !mergeConfig.RequiredReviews.RequireCodeOwnerReview {
+		codeOwners, err := mergeConfig.codeOwners(ctx)
+		if err != nil {
+			return errors.Wrap(err, "failed to load code owners")
+		}
+		approved, reason, err := HasCodeOwnerApproval(ctx, pullCtx, codeOwners, RequiredReviews{})
+		if err != nil {
+			return errors.Wrap(err, "failed to determine if pull request has code owner approval")
+		}
+		if approved {
+			decision.add("branch protection code owner review", Pass, reason)
+		} else {
+			decision.add("branch protection code owner review", Pending, reason)
+		}
+	}
+	return nil
 }
@@ -22,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/ridge/bulldozer/pull"
 	"github.com/ridge/bulldozer/pull/pulltest"
 )
 
@@ -104,24 +105,24 @@ func TestShouldMerge(t *testing.T) {
 
 	t.Run("fullCommentShouldMerge", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
-			CommentValue: []string{"FULL_COMMENT_PLZ_MERGE"},
+			CommentValue: []*pull.Comment{{Body: "FULL_COMMENT_PLZ_MERGE"}},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.True(t, actualShouldMerge)
+		assert.True(t, decision.Passed())
 	})
 
 	t.Run("partialCommentShouldntMerge", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
-			CommentValue: []string{"This is not a FULL_COMMENT_PLZ_MERGE"},
+			CommentValue: []*pull.Comment{{Body: "This is not a FULL_COMMENT_PLZ_MERGE"}},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("labelShouldMerge", func(t *testing.T) {
@@ -129,10 +130,10 @@ func TestShouldMerge(t *testing.T) {
 			LabelValue: []string{"LABEL_MERGE"},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.True(t, actualShouldMerge)
+		assert.True(t, decision.Passed())
 	})
 
 	t.Run("labelShouldMergeCaseInsensitive", func(t *testing.T) {
@@ -140,43 +141,43 @@ func TestShouldMerge(t *testing.T) {
 			LabelValue: []string{"LABEL_merGE"},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.True(t, actualShouldMerge)
+		assert.True(t, decision.Passed())
 	})
 
 	t.Run("noContextShouldntMerge", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
 			LabelValue:   []string{"NOT_A_LABEL"},
-			CommentValue: []string{"commenta", "foo", "bar", "baz\n\rbaz"},
+			CommentValue: []*pull.Comment{{Body: "commenta"}, {Body: "foo"}, {Body: "bar"}, {Body: "baz\n\rbaz"}},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("noMatchingShouldntMerge", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("blacklistOverridesWhitelist", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
 			LabelValue:   []string{"LABEL2_MERGE"},
-			CommentValue: []string{"NO_WAY"},
+			CommentValue: []*pull.Comment{{Body: "NO_WAY"}},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("labelCausesBlacklist", func(t *testing.T) {
@@ -184,10 +185,10 @@ func TestShouldMerge(t *testing.T) {
 			LabelValue: []string{"LABEL_NOMERGE"},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("labelCausesBlacklistCaseInsensitive", func(t *testing.T) {
@@ -195,59 +196,59 @@ func TestShouldMerge(t *testing.T) {
 			LabelValue: []string{"LABEL_nomERGE"},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("substringCausesWhitelist", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
 			LabelValue:   []string{"NOT_A_LABEL"},
-			CommentValue: []string{"a comment", "another comment", "this is good :+1: yep"},
+			CommentValue: []*pull.Comment{{Body: "a comment"}, {Body: "another comment"}, {Body: "this is good :+1: yep"}},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.True(t, actualShouldMerge)
+		assert.True(t, decision.Passed())
 	})
 
 	t.Run("substringCausesBlacklist", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
 			LabelValue:   []string{"LABEL_NOMERGE"},
-			CommentValue: []string{"a comment", "another comment", "this is no good nope\n\r:-1:"},
+			CommentValue: []*pull.Comment{{Body: "a comment"}, {Body: "another comment"}, {Body: "this is no good nope\n\r:-1:"}},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.False(t, decision.Passed())
 	})
 
 	t.Run("failClosedOnLabelErr", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
 			LabelValue:    []string{"LABEL_NOMERGE"},
-			CommentValue:  []string{"a comment", "another comment", "this is no good nope\n\r:-1:"},
+			CommentValue:  []*pull.Comment{{Body: "a comment"}, {Body: "another comment"}, {Body: "this is no good nope\n\r:-1:"}},
 			LabelErrValue: errors.New("failure"),
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.NotNil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.Nil(t, decision)
 	})
 
 	t.Run("failClosedOnCommentErr", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
-			CommentValue:    []string{"a comment", "another comment", "this is no good nope\n\r:-1:"},
+			CommentValue:    []*pull.Comment{{Body: "a comment"}, {Body: "another comment"}, {Body: "this is no good nope\n\r:-1:"}},
 			CommentErrValue: errors.New("failure"),
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.NotNil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.Nil(t, decision)
 	})
 
 	t.Run("failClosedOnRequiredStatusCheckErr", func(t *testing.T) {
@@ -256,10 +257,10 @@ func TestShouldMerge(t *testing.T) {
 			RequiredStatusesErrValue: errors.New("failure"),
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.NotNil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.Nil(t, decision)
 	})
 
 	t.Run("failClosedOnSuccessStatusCheckErr", func(t *testing.T) {
@@ -268,35 +269,128 @@ func TestShouldMerge(t *testing.T) {
 			StatusesErrValue: errors.New("failure"),
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.NotNil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.Nil(t, decision)
 	})
 
 	t.Run("allStatusChecksMet", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
-			LabelValue:            []string{"LABEL_MERGE"},
-			SuccessStatusesValue:  []string{"StatusCheckA", "StatusCheckB"},
+			LabelValue: []string{"LABEL_MERGE"},
+			StatusesValue: []*pull.Status{
+				{Name: "StatusCheckA", Conclusion: "success"},
+				{Name: "StatusCheckB", Conclusion: "success"},
+			},
 			RequiredStatusesValue: []string{"StatusCheckB", "StatusCheckA"},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.True(t, actualShouldMerge)
+		assert.True(t, decision.Passed())
 	})
 
 	t.Run("notAllStatusChecksMet", func(t *testing.T) {
 		pc := &pulltest.MockPullContext{
-			LabelValue:            []string{"LABEL_MERGE"},
-			SuccessStatusesValue:  []string{"StatusCheckA"},
+			LabelValue: []string{"LABEL_MERGE"},
+			StatusesValue: []*pull.Status{
+				{Name: "StatusCheckA", Conclusion: "success"},
+			},
 			RequiredStatusesValue: []string{"StatusCheckA", "StatusCheckB"},
 		}
 
-		actualShouldMerge, err := ShouldMergePR(ctx, pc, mergeConfig)
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
+
+		require.Nil(t, err)
+		assert.False(t, decision.Passed())
+		assert.Equal(t, Pending, decision.Result())
+		assert.Equal(t, []Condition{
+			{Name: `status "StatusCheckB"`, Result: Pending, Reason: "status check has not been reported"},
+		}, decision.Blocking())
+	})
+
+	t.Run("branchProtectionWaitsForOtherConditions", func(t *testing.T) {
+		pc := &pulltest.MockPullContext{
+			LabelValue:               []string{"LABEL_MERGE"},
+			RequiredStatusesValue:    []string{"build"},
+			ReviewProtectionErrValue: errors.New("branch protection should not be loaded"),
+		}
+
+		decision, err := ShouldMergePR(ctx, pc, mergeConfig)
 
 		require.Nil(t, err)
-		assert.False(t, actualShouldMerge)
+		assert.Equal(t, []Condition{{
+			Name:   `status "build"`,
+			Result: Pending,
+			Reason: "status check has not been reported",
+		}}, decision.Blocking())
 	})
 }
+
+func TestProtectionReviewConditions(t *testing.T) {
+	ctx := context.Background()
+
+	reviews := []*pull.Review{
+		{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+	}
+
+	tests := map[string]struct {
+		Protection      *pull.ReviewProtection
+		RequiredReviews RequiredReviews
+		Conditions      []Condition
+		Loads           int
+	}{
+		"noProtection": {
+			Protection: nil,
+		},
+		"reviewsMissing": {
+			Protection: &pull.ReviewProtection{RequiredApprovals: 2},
+			Conditions: []Condition{
+				{Name: "branch protection reviews", Result: Pending, Reason: "pull request has 1 of 2 approvals required by branch protection"},
+			},
+		},
+		"codeOwnerReviewMissing": {
+			Protection: &pull.ReviewProtection{RequiredApprovals: 1, RequireCodeOwnerReviews: true},
+			Conditions: []Condition{
+				{Name: "branch protection reviews", Result: Pass, Reason: "pull request has 1 of 1 approvals required by branch protection"},
+				{Name: "branch protection code owner review", Result: Pending, Reason: "pull request is missing approvals from code owners of api/server.go (@bob)"},
+			},
+			Loads: 1,
+		},
+		"codeOwnerReviewRequiredByConfig": {
+			Protection:      &pull.ReviewProtection{RequiredApprovals: 1, RequireCodeOwnerReviews: true},
+			RequiredReviews: RequiredReviews{RequireCodeOwnerReview: true},
+			Conditions: []Condition{
+				{Name: "branch protection reviews", Result: Pass, Reason: "pull request has 1 of 1 approvals required by branch protection"},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{
+				HeadSHAValue:          "head",
+				FilesValue:            []string{"api/server.go"},
+				ReviewsValue:          reviews,
+				PermissionValue:       map[string]string{"alice": "write"},
+				ReviewProtectionValue: test.Protection,
+			}
+
+			loads := 0
+			mergeConfig := MergeConfig{
+				RequiredReviews: test.RequiredReviews,
+				loadCodeOwners: func(ctx context.Context) (*CodeOwners, error) {
+					loads++
+					return ParseCodeOwners([]byte("/api/ @bob\n")), nil
+				},
+			}
+
+			decision := &Decision{}
+			err := protectionReviewConditions(ctx, pc, mergeConfig, decision)
+			require.NoError(t, err)
+			assert.Equal(t, test.Conditions, decision.Conditions)
+			assert.Equal(t, test.Loads, loads, "unexpected number of CODEOWNERS loads")
+		})
+	}
+}
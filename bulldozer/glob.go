@@ -0,0 +1,94 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// compileGlob converts a glob pattern to a regular expression matching the
+// whole input. A "*" matches any sequence of characters except "/", "?"
+// matches any single character except "/" and "**" matches any sequence of
+// characters, including "/". A "**" that forms a complete path segment also
+// matches zero segments, so "docs/**" matches "docs/a/b.md" and "**/go.mod"
+// matches "go.mod". Character classes like "[abc]" are supported.
+func compileGlob(pattern string) (*regexp.Regexp, error) {
+	var rx strings.Builder
+	rx.WriteString("^")
+
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				startOfSegment := i == 0 || pattern[i-1] == '/'
+				switch {
+				case startOfSegment && i+2 < len(pattern) && pattern[i+2] == '/':
+					rx.WriteString("(?:.*/)?")
+					i += 2
+				case startOfSegment && i+2 == len(pattern) && i > 0:
+					// "/**" at the end also matches the directory itself
+					s := rx.String()
+					rx.Reset()
+					rx.WriteString(strings.TrimSuffix(s, "/"))
+					rx.WriteString("(?:/.*)?")
+					i++
+				default:
+					rx.WriteString(".*")
+					i++
+				}
+			} else {
+				rx.WriteString("[^/]*")
+			}
+		case '?':
+			rx.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(pattern[i+1:], ']')
+			if end < 0 {
+				return nil, errors.Errorf("invalid glob %q: unterminated character class", pattern)
+			}
+			class := pattern[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			rx.WriteString("[" + class + "]")
+			i += end + 1
+		default:
+			rx.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	rx.WriteString("$")
+	compiled, err := regexp.Compile(rx.String())
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid glob %q", pattern)
+	}
+	return compiled, nil
+}
+
+func compileGlobs(patterns []string) ([]*regexp.Regexp, error) {
+	rxs := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		rx, err := compileGlob(pattern)
+		if err != nil {
+			return nil, err
+		}
+		rxs[i] = rx
+	}
+	return rxs, nil
+}
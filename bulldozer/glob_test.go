@@ -0,0 +1,88 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompileGlob(t *testing.T) {
+	tests := map[string]struct {
+		Pattern  string
+		Matches  []string
+		Excludes []string
+	}{
+		"literal": {
+			Pattern:  "README.md",
+			Matches:  []string{"README.md"},
+			Excludes: []string{"docs/README.md", "README.mdx", "READMExmd"},
+		},
+		"star": {
+			Pattern:  "renovate/*",
+			Matches:  []string{"renovate/lodash-4.x"},
+			Excludes: []string{"renovate/npm/lodash", "renovate", "xrenovate/a"},
+		},
+		"trailingDoubleStar": {
+			Pattern:  "migrations/**",
+			Matches:  []string{"migrations", "migrations/001.sql", "migrations/a/b/002.sql"},
+			Excludes: []string{"db/migrations/001.sql", "migrations2/001.sql"},
+		},
+		"leadingDoubleStar": {
+			Pattern:  "**/go.mod",
+			Matches:  []string{"go.mod", "tools/go.mod", "a/b/go.mod"},
+			Excludes: []string{"go.mod.bak", "ago.mod"},
+		},
+		"middleDoubleStar": {
+			Pattern:  "docs/**/*.md",
+			Matches:  []string{"docs/index.md", "docs/a/b/c.md"},
+			Excludes: []string{"docs/index.txt", "src/docs/index.md"},
+		},
+		"questionMark": {
+			Pattern:  "v?",
+			Matches:  []string{"v1", "v2"},
+			Excludes: []string{"v10", "v/"},
+		},
+		"characterClass": {
+			Pattern:  "test (ubuntu, 1.2[0-9])",
+			Matches:  []string{"test (ubuntu, 1.21)"},
+			Excludes: []string{"test (ubuntu, 1.3)", "test (ubuntu, 1.2x)"},
+		},
+		"negatedCharacterClass": {
+			Pattern:  "[!a]*",
+			Matches:  []string{"bcd"},
+			Excludes: []string{"abc"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			rx, err := compileGlob(test.Pattern)
+			require.NoError(t, err)
+
+			for _, s := range test.Matches {
+				assert.True(t, rx.MatchString(s), "expected %q to match %q", test.Pattern, s)
+			}
+			for _, s := range test.Excludes {
+				assert.False(t, rx.MatchString(s), "expected %q not to match %q", test.Pattern, s)
+			}
+		})
+	}
+
+	_, err := compileGlob("release/[0-9")
+	assert.Error(t, err)
+}
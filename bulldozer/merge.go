@@ -31,10 +31,16 @@ import (
 
 const MaxPullRequestPollCount = 5
 
+// ErrEnqueued is returned by Mergers that added the pull request to a merge
+// queue instead of merging it. GitHub merges the pull request later.
+var ErrEnqueued = errors.New("pull request was added to the merge queue")
+
 type Merger interface {
 	// Merge merges the pull request in the context using the commit message
-	// and options. It returns the SHA of the merge commit on success.
-	Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage) (string, error)
+	// and options. It returns the SHA of the merge commit on success. If the
+	// head of the pull request is no longer headSHA, the commit that was
+	// evaluated, the merge fails with a 409 response.
+	Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error)
 
 	// DeleteHead deletes the head branch of the pull request in the context.
 	DeleteHead(ctx context.Context, pullCtx pull.Context) error
@@ -59,9 +65,10 @@ func NewGitHubMerger(client *github.Client) Merger {
 	}
 }
 
-func (m *GitHubMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage) (string, error) {
+func (m *GitHubMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
 	opts := github.PullRequestOptions{
 		CommitTitle: msg.Title,
+		SHA:         headSHA,
 		MergeMethod: string(method),
 	}
 
@@ -89,6 +96,40 @@ func (m *GitHubMerger) ChangeBase(ctx context.Context, pullCtx pull.Context, dep
 	return err
 }
 
+// IsHeadChanged returns true if the merge failed because the head of the pull
+// request is no longer the commit that was evaluated.
+func IsHeadChanged(err error) bool {
+	gerr, ok := errors.Cause(err).(*github.ErrorResponse)
+	return ok && gerr.Response != nil && gerr.Response.StatusCode == http.StatusConflict
+}
+
+// ReevaluatingMerger evaluates pull requests again when their head changed
+// between the evaluation and the merge.
+type ReevaluatingMerger struct {
+	Merger
+
+	reevaluate func()
+}
+
+// NewReevaluatingMerger returns a Merger that calls the reevaluate function
+// when a merge with another Merger fails because the head of the pull request
+// changed.
+func NewReevaluatingMerger(merger Merger, reevaluate func()) Merger {
+	return &ReevaluatingMerger{
+		Merger:     merger,
+		reevaluate: reevaluate,
+	}
+}
+
+func (m *ReevaluatingMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
+	sha, err := m.Merger.Merge(ctx, pullCtx, method, msg, headSHA)
+	if IsHeadChanged(err) {
+		zerolog.Ctx(ctx).Info().Msgf("Head of %q changed from %s since it was evaluated, evaluating it again", pullCtx.Locator(), headSHA)
+		m.reevaluate()
+	}
+	return sha, err
+}
+
 // PushRestrictionMerger delegates merge operations to different Mergers based
 // on whether or not the pull requests targets a branch with push restrictions.
 type PushRestrictionMerger struct {
@@ -103,7 +144,7 @@ func NewPushRestrictionMerger(normal, restricted Merger) Merger {
 	}
 }
 
-func (m *PushRestrictionMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage) (string, error) {
+func (m *PushRestrictionMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
 	restricted, err := pullCtx.PushRestrictions(ctx)
 	if err != nil {
 		return "", err
@@ -111,9 +152,9 @@ func (m *PushRestrictionMerger) Merge(ctx context.Context, pullCtx pull.Context,
 
 	if restricted {
 		zerolog.Ctx(ctx).Info().Msg("Target branch has push restrictions, using restricted client for merge")
-		return m.Restricted.Merge(ctx, pullCtx, method, msg)
+		return m.Restricted.Merge(ctx, pullCtx, method, msg, headSHA)
 	}
-	return m.Normal.Merge(ctx, pullCtx, method, msg)
+	return m.Normal.Merge(ctx, pullCtx, method, msg, headSHA)
 }
 
 func (m *PushRestrictionMerger) DeleteHead(ctx context.Context, pullCtx pull.Context) error {
@@ -160,51 +201,10 @@ func retargetDependentPullRequests(ctx context.Context, pullCtx pull.Context, me
 func MergePR(ctx context.Context, pullCtx pull.Context, merger Merger, mergeConfig MergeConfig) error {
 	logger := zerolog.Ctx(ctx)
 
-	base, head := pullCtx.Branches()
-	mergeMethod := mergeConfig.Method
-
-	if branchMergeMethod, ok := mergeConfig.BranchMethod[base]; ok {
-		mergeMethod = branchMergeMethod
-	}
-	if !isValidMergeMethod(mergeMethod) {
-		mergeMethod = MergeCommit
-	}
-
-	commitMsg := CommitMessage{}
-	if mergeMethod == SquashAndMerge {
-		opt := mergeConfig.Options.Squash
-		if opt == nil {
-			logger.Info().Msgf("No squash options defined; using defaults")
-			opt = &SquashOptions{}
-		}
-
-		if opt.Title == "" {
-			opt.Title = PullRequestTitle
-		}
-		if opt.Body == "" {
-			opt.Body = EmptyBody
-		}
-
-		message, err := calculateCommitMessage(ctx, pullCtx, *opt)
-		if err != nil {
-			return errors.Wrap(err, "failed to calculate commit message")
-		}
-		// github-go erroneously omits commit message from the request
-		// if it is an empty string. This causes GitHub to fill in a
-		// default merge commit message (summary of all commits in the
-		// PR).
-		//
-		// Work around this problem.
-		if message == "" {
-			message = " "
-		}
-		commitMsg.Message = message
-
-		title, err := calculateCommitTitle(ctx, pullCtx, *opt)
-		if err != nil {
-			return errors.Wrap(err, "failed to calculate commit title")
-		}
-		commitMsg.Title = title
+	headSHA := pullCtx.HeadSHA()
+	mergeMethod, commitMsg, err := mergeMethodAndMessage(ctx, pullCtx, mergeConfig, headSHA)
+	if err != nil {
+		return err
 	}
 
 	go func(ctx context.Context) {
@@ -237,7 +237,11 @@ func MergePR(ctx context.Context, pullCtx pull.Context, merger Merger, mergeConf
 
 			// Try a merge, a 405 is expected if required reviews are not satisfied
 			logger.Info().Msgf("Attempting to merge pull request with method %s", mergeMethod)
-			sha, err := merger.Merge(ctx, pullCtx, mergeMethod, commitMsg)
+			sha, err := merger.Merge(ctx, pullCtx, mergeMethod, commitMsg, headSHA)
+			if errors.Cause(err) == ErrEnqueued {
+				logger.Info().Msg("Pull request was added to the merge queue")
+				return
+			}
 			if err != nil {
 				gerr, ok := errors.Cause(err).(*github.ErrorResponse)
 				if !ok {
@@ -250,7 +254,7 @@ func MergePR(ctx context.Context, pullCtx pull.Context, merger Merger, mergeConf
 					logger.Info().Msgf("Merge rejected due to unsatisfied condition: %q", gerr.Message)
 					return
 				case http.StatusConflict:
-					logger.Info().Msgf("Merge rejected due to being invalid: %q", gerr.Message)
+					logger.Info().Msgf("Merge rejected because the head of the pull request changed: %q", gerr.Message)
 					return
 				default:
 					logger.Error().Msgf("Merge failed with unexpected status: %d: %q", gerr.Response.StatusCode, gerr.Message)
@@ -259,43 +263,7 @@ func MergePR(ctx context.Context, pullCtx pull.Context, merger Merger, mergeConf
 			}
 
 			logger.Info().Msgf("Successfully merged pull request as SHA %s", sha)
-
-			// if head is qualified (contains ":"), PR is from a fork and we don't have delete permission
-			if !strings.ContainsRune(head, ':') {
-				ref := fmt.Sprintf("refs/heads/%s", head)
-				if mergeConfig.DeleteAfterMerge {
-					// check other open PRs to make sure that nothing is trying to merge into the ref we're about to delete
-					isTargeted, err := pullCtx.IsTargeted(ctx)
-					if err != nil {
-						logger.Error().Err(err).Msgf("Unable to determine if ref %s is targeted by other open pull requests before deletion", ref)
-						return
-					}
-					if isTargeted {
-						if mergeConfig.RetargetDependentPullRequests {
-							if err := retargetDependentPullRequests(ctx, pullCtx, merger); err != nil {
-								logger.Error().Err(err).Msgf("Failed to retarget dependent PRs for ref %s", ref)
-								return
-							}
-						} else {
-							logger.Info().Msgf("Unable to delete ref %s after merging %q because there are open PRs against this ref", ref, pullCtx.Locator())
-							return
-						}
-					}
-
-					logger.Info().Msgf("Attempting to delete ref %s", ref)
-					if err := merger.DeleteHead(ctx, pullCtx); err != nil {
-						logger.Error().Err(err).Msgf("Failed to delete ref %s on %q", ref, pullCtx.Locator())
-						return
-					}
-
-					logger.Info().Msgf("Successfully deleted ref %s on %q", ref, pullCtx.Locator())
-				} else {
-					logger.Debug().Msgf("Not deleting ref %s, delete_after_merge is not enabled", ref)
-				}
-			} else {
-				logger.Debug().Msg("Pull Request is from a fork, not deleting")
-			}
-
+			CleanupAfterMerge(ctx, pullCtx, merger, mergeConfig)
 			return
 		}
 	}(zerolog.Ctx(ctx).WithContext(context.Background()))
@@ -303,11 +271,112 @@ func MergePR(ctx context.Context, pullCtx pull.Context, merger Merger, mergeConf
 	return nil
 }
 
+// mergeMethodAndMessage returns the merge method for the target branch of the
+// pull request and the commit message to merge it with at the given head.
+func mergeMethodAndMessage(ctx context.Context, pullCtx pull.Context, mergeConfig MergeConfig, headSHA string) (MergeMethod, CommitMessage, error) {
+	logger := zerolog.Ctx(ctx)
+
+	base, _ := pullCtx.Branches()
+	mergeMethod := mergeConfig.Method
+
+	if branchMergeMethod, ok := mergeConfig.BranchMethod[base]; ok {
+		mergeMethod = branchMergeMethod
+	}
+	if !isValidMergeMethod(mergeMethod) {
+		mergeMethod = MergeCommit
+	}
+
+	commitMsg := CommitMessage{}
+	if mergeMethod == SquashAndMerge {
+		opt := mergeConfig.Options.Squash
+		if opt == nil {
+			logger.Info().Msgf("No squash options defined; using defaults")
+			opt = &SquashOptions{}
+		}
+
+		if opt.Title == "" {
+			opt.Title = PullRequestTitle
+		}
+		if opt.Body == "" {
+			opt.Body = EmptyBody
+		}
+
+		message, err := calculateCommitMessage(ctx, pullCtx, *opt, headSHA)
+		if err != nil {
+			return "", CommitMessage{}, errors.Wrap(err, "failed to calculate commit message")
+		}
+		// github-go erroneously omits commit message from the request
+		// if it is an empty string. This causes GitHub to fill in a
+		// default merge commit message (summary of all commits in the
+		// PR).
+		//
+		// Work around this problem.
+		if message == "" {
+			message = " "
+		}
+		commitMsg.Message = message
+
+		title, err := calculateCommitTitle(ctx, pullCtx, *opt, headSHA)
+		if err != nil {
+			return "", CommitMessage{}, errors.Wrap(err, "failed to calculate commit title")
+		}
+		commitMsg.Title = title
+	}
+
+	return mergeMethod, commitMsg, nil
+}
+
+// CleanupAfterMerge deletes the head branch of a merged pull request if the
+// configuration enables it, retargeting dependent pull requests if needed.
+func CleanupAfterMerge(ctx context.Context, pullCtx pull.Context, merger Merger, mergeConfig MergeConfig) {
+	logger := zerolog.Ctx(ctx)
+
+	_, head := pullCtx.Branches()
+
+	// if head is qualified (contains ":"), PR is from a fork and we don't have delete permission
+	if strings.ContainsRune(head, ':') {
+		logger.Debug().Msg("Pull Request is from a fork, not deleting")
+		return
+	}
+
+	ref := fmt.Sprintf("refs/heads/%s", head)
+	if !mergeConfig.DeleteAfterMerge {
+		logger.Debug().Msgf("Not deleting ref %s, delete_after_merge is not enabled", ref)
+		return
+	}
+
+	// check other open PRs to make sure that nothing is trying to merge into the ref we're about to delete
+	isTargeted, err := pullCtx.IsTargeted(ctx)
+	if err != nil {
+		logger.Error().Err(err).Msgf("Unable to determine if ref %s is targeted by other open pull requests before deletion", ref)
+		return
+	}
+	if isTargeted {
+		if mergeConfig.RetargetDependentPullRequests {
+			if err := retargetDependentPullRequests(ctx, pullCtx, merger); err != nil {
+				logger.Error().Err(err).Msgf("Failed to retarget dependent PRs for ref %s", ref)
+				return
+			}
+		} else {
+			logger.Info().Msgf("Unable to delete ref %s after merging %q because there are open PRs against this ref", ref, pullCtx.Locator())
+			return
+		}
+	}
+
+	logger.Info().Msgf("Attempting to delete ref %s", ref)
+	if err := merger.DeleteHead(ctx, pullCtx); err != nil {
+		logger.Error().Err(err).Msgf("Failed to delete ref %s on %q", ref, pullCtx.Locator())
+		return
+	}
+
+	logger.Info().Msgf("Successfully deleted ref %s on %q", ref, pullCtx.Locator())
+}
+
 func isValidMergeMethod(input MergeMethod) bool {
 	return input == SquashAndMerge || input == RebaseAndMerge || input == MergeCommit
 }
 
-func calculateCommitMessage(ctx context.Context, pullCtx pull.Context, option SquashOptions) (string, error) {
+func calculateCommitMessage(ctx context.Context, pullCtx pull.Context, option SquashOptions, headSHA string) (string, error) {
 	commitMessage := ""
 	switch option.Body {
 	case PullRequestBody:
@@ -334,7 +403,7 @@ func calculateCommitMessage(ctx context.Context, pullCtx pull.Context, option Sq
 			commitMessage = pullCtx.Body()
 		}
 	case SummarizeCommits:
-		summarizedMessages, err := summarizeCommitMessages(ctx, pullCtx)
+		summarizedMessages, err := summarizeCommitMessages(ctx, pullCtx, headSHA)
 		if err != nil {
 			return "", errors.Wrap(err, "failed to summarize pull request commit messages")
 		}
@@ -345,13 +414,13 @@ func calculateCommitMessage(ctx context.Context, pullCtx pull.Context, option Sq
 	return commitMessage, nil
 }
 
-func calculateCommitTitle(ctx context.Context, pullCtx pull.Context, option SquashOptions) (string, error) {
+func calculateCommitTitle(ctx context.Context, pullCtx pull.Context, option SquashOptions, headSHA string) (string, error) {
 	var title string
 	switch option.Title {
 	case PullRequestTitle:
 		title = pullCtx.Title()
 	case FirstCommitTitle:
-		commits, err := pullCtx.Commits(ctx)
+		commits, err := commitsAt(ctx, pullCtx, headSHA)
 		if err != nil {
 			return "", err
 		}
@@ -366,8 +435,8 @@ func calculateCommitTitle(ctx context.Context, pullCtx pull.Context, option Squa
 	return title, nil
 }
 
-func summarizeCommitMessages(ctx context.Context, pullCtx pull.Context) (string, error) {
-	commits, err := pullCtx.Commits(ctx)
+func summarizeCommitMessages(ctx context.Context, pullCtx pull.Context, headSHA string) (string, error) {
+	commits, err := commitsAt(ctx, pullCtx, headSHA)
 	if err != nil {
 		return "", err
 	}
@@ -378,3 +447,22 @@ func summarizeCommitMessages(ctx context.Context, pullCtx pull.Context) (string,
 	}
 	return builder.String(), nil
 }
+
+// commitsAt returns the commits of the pull request up to the given head, so
+// that commits pushed after the pull request was evaluated are not part of
+// its commit message. If the head is not one of the commits, for example
+// after a force push, all commits are returned; GitHub rejects the merge in
+// this case, as the head changed.
+func commitsAt(ctx context.Context, pullCtx pull.Context, headSHA string) ([]*pull.Commit, error) {
+	commits, err := pullCtx.Commits(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, c := range commits {
+		if c.SHA == headSHA {
+			return commits[:i+1], nil
+		}
+	}
+	return commits, nil
+}
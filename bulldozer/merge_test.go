@@ -16,6 +16,7 @@ package bulldozer
 
 import (
 	"context"
+	"net/http"
 	"testing"
 
 	"github.com/google/go-github/v43/github"
@@ -36,7 +37,7 @@ type MockMerger struct {
 	ChangeBaseError error
 }
 
-func (m *MockMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage) (string, error) {
+func (m *MockMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
 	m.MergeCount++
 	return "deadbeef", m.MergeError
 }
@@ -97,7 +98,7 @@ func TestCalculateCommitTitle(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			output, err := calculateCommitTitle(ctx, test.PullContext, SquashOptions{Title: test.Strategy})
+			output, err := calculateCommitTitle(ctx, test.PullContext, SquashOptions{Title: test.Strategy}, "")
 			require.NoError(t, err)
 			assert.Equal(t, test.Output, output, "calculated title is incorrect")
 		})
@@ -121,6 +122,7 @@ func TestCalculateCommitMessage(t *testing.T) {
 		Delimiter   string
 		EndMarker   string
 		EndMarkerRx string
+		HeadSHA     string
 		Output      string
 	}{
 		"emptyBody": {
@@ -133,6 +135,12 @@ func TestCalculateCommitMessage(t *testing.T) {
 			Strategy:    SummarizeCommits,
 			Output:      "* The first commit message!\n* The second commit message!\n* The third commit message!\n",
 		},
+		"summarizeCommitsAtHead": {
+			PullContext: defaultPullContext,
+			Strategy:    SummarizeCommits,
+			HeadSHA:     "89aec3244253260261351047f0bf6d9b7626c4f6",
+			Output:      "* The first commit message!\n* The second commit message!\n",
+		},
 		"pullRequestBody": {
 			PullContext: defaultPullContext,
 			Strategy:    PullRequestBody,
@@ -188,7 +196,7 @@ func TestCalculateCommitMessage(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			output, err := calculateCommitMessage(ctx, test.PullContext, SquashOptions{Body: test.Strategy, MessageDelimiter: test.Delimiter, MessageEndMarker: test.EndMarker, MessageEndMarkerRx: test.EndMarkerRx})
+			output, err := calculateCommitMessage(ctx, test.PullContext, SquashOptions{Body: test.Strategy, MessageDelimiter: test.Delimiter, MessageEndMarker: test.EndMarker, MessageEndMarkerRx: test.EndMarkerRx}, test.HeadSHA)
 			require.NoError(t, err)
 			assert.Equal(t, test.Output, output, "calculated body is incorrect")
 		})
@@ -203,7 +211,7 @@ func TestPushRestrictionMerger(t *testing.T) {
 	ctx := context.Background()
 	pullCtx := &pulltest.MockPullContext{}
 
-	_, _ = merger.Merge(ctx, pullCtx, SquashAndMerge, CommitMessage{})
+	_, _ = merger.Merge(ctx, pullCtx, SquashAndMerge, CommitMessage{}, "abc")
 	assert.Equal(t, 1, normal.MergeCount, "normal merge was not called")
 	assert.Equal(t, 0, restricted.MergeCount, "restricted merge was incorrectly called")
 
@@ -213,7 +221,7 @@ func TestPushRestrictionMerger(t *testing.T) {
 
 	pullCtx.PushRestrictionsValue = true
 
-	_, _ = merger.Merge(ctx, pullCtx, SquashAndMerge, CommitMessage{})
+	_, _ = merger.Merge(ctx, pullCtx, SquashAndMerge, CommitMessage{}, "abc")
 	assert.Equal(t, 1, normal.MergeCount, "normal merge was incorrectly called")
 	assert.Equal(t, 1, restricted.MergeCount, "restricted merge was not called")
 
@@ -221,3 +229,31 @@ func TestPushRestrictionMerger(t *testing.T) {
 	assert.Equal(t, 1, normal.DeleteCount, "normal delete was incorrectly called")
 	assert.Equal(t, 1, restricted.DeleteCount, "restricted delete was not called")
 }
+
+func TestReevaluatingMerger(t *testing.T) {
+	ctx := context.Background()
+	pullCtx := &pulltest.MockPullContext{}
+
+	reevaluated := 0
+	normal := &MockMerger{}
+	merger := NewReevaluatingMerger(normal, func() { reevaluated++ })
+
+	_, err := merger.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, 0, reevaluated, "pull request was incorrectly evaluated again")
+
+	normal.MergeError = &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusMethodNotAllowed},
+	}
+	_, err = merger.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+	require.Error(t, err)
+	assert.Equal(t, 0, reevaluated, "pull request was incorrectly evaluated again")
+
+	normal.MergeError = &github.ErrorResponse{
+		Response: &http.Response{StatusCode: http.StatusConflict},
+	}
+	_, err = merger.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+	require.Error(t, err)
+	assert.True(t, IsHeadChanged(err))
+	assert.Equal(t, 1, reevaluated, "pull request was not evaluated again")
+}
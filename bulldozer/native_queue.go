@@ -0,0 +1,206 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/shurcooL/githubv4"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+// EnqueuePullRequestInput is the input of the enqueuePullRequest mutation.
+// The githubv4 package does not define it yet; the GraphQL type of the input
+// is derived from the name of this type.
+type EnqueuePullRequestInput struct {
+	PullRequestID githubv4.ID `json:"pullRequestId"`
+
+	// ExpectedHeadOid makes GitHub reject the mutation if the head of the
+	// pull request changed since it was evaluated.
+	ExpectedHeadOid *githubv4.GitObjectID `json:"expectedHeadOid,omitempty"`
+}
+
+// DefaultNativeQueueCacheTTL is how long a NativeQueueCache remembers that a
+// target branch does not require a merge queue.
+const DefaultNativeQueueCacheTTL = 10 * time.Minute
+
+// NativeQueueCache remembers the target branches that do not require a merge
+// queue, so that merging their pull requests does not need an additional
+// query every time.
+type NativeQueueCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	branches map[QueueKey]time.Time
+}
+
+func NewNativeQueueCache(ttl time.Duration) *NativeQueueCache {
+	return &NativeQueueCache{
+		ttl:      ttl,
+		branches: make(map[QueueKey]time.Time),
+	}
+}
+
+// withoutQueue returns true if the target branch recently did not require a
+// merge queue.
+func (c *NativeQueueCache) withoutQueue(key QueueKey) bool {
+	if c == nil {
+		return false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	expires, ok := c.branches[key]
+	if ok && !now().Before(expires) {
+		delete(c.branches, key)
+		return false
+	}
+	return ok
+}
+
+func (c *NativeQueueCache) setWithoutQueue(key QueueKey, withoutQueue bool) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if withoutQueue {
+		c.branches[key] = now().Add(c.ttl)
+	} else {
+		delete(c.branches, key)
+	}
+}
+
+// NativeQueueMerger adds pull requests to the merge queue of GitHub when their
+// target branch requires one, as GitHub rejects merges of these pull
+// requests. Other pull requests are merged by the embedded Merger.
+type NativeQueueMerger struct {
+	Merger
+
+	v4client *githubv4.Client
+	cache    *NativeQueueCache
+}
+
+// NewNativeQueueMerger returns a NativeQueueMerger. If cache is not nil, it is
+// used to skip the merge queue lookup for branches that recently did not
+// require a merge queue.
+func NewNativeQueueMerger(merger Merger, v4client *githubv4.Client, cache *NativeQueueCache) Merger {
+	return &NativeQueueMerger{
+		Merger:   merger,
+		v4client: v4client,
+		cache:    cache,
+	}
+}
+
+// Merge enqueues the pull request and returns ErrEnqueued if the target
+// branch requires a merge queue. The merge queue uses its own merge method
+// and commit message, so the method and message are ignored in this case.
+func (m *NativeQueueMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
+	logger := zerolog.Ctx(ctx)
+
+	key := QueueKeyFor(pullCtx)
+	if m.cache.withoutQueue(key) {
+		return m.mergeWithoutQueue(ctx, pullCtx, method, msg, headSHA)
+	}
+
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				ID             githubv4.ID
+				IsInMergeQueue bool
+			} `graphql:"pullRequest(number: $number)"`
+
+			MergeQueue *struct {
+				ID githubv4.ID
+			} `graphql:"mergeQueue(branch: $branch)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]interface{}{
+		"owner":  githubv4.String(pullCtx.Owner()),
+		"name":   githubv4.String(pullCtx.Repo()),
+		"number": githubv4.Int(pullCtx.Number()),
+		"branch": githubv4.String(pullCtx.BaseRef()),
+	}
+	if err := m.v4client.Query(ctx, &q, vars); err != nil {
+		return "", errors.Wrapf(err, "failed to determine if %s requires a merge queue", pullCtx.BaseRef())
+	}
+
+	m.cache.setWithoutQueue(key, q.Repository.MergeQueue == nil)
+	if q.Repository.MergeQueue == nil {
+		return m.mergeWithoutQueue(ctx, pullCtx, method, msg, headSHA)
+	}
+	if q.Repository.PullRequest.IsInMergeQueue {
+		logger.Debug().Msgf("%s is already in the merge queue", pullCtx.Locator())
+		return "", ErrEnqueued
+	}
+
+	var mutation struct {
+		EnqueuePullRequest struct {
+			MergeQueueEntry struct {
+				Position int
+			}
+		} `graphql:"enqueuePullRequest(input: $input)"`
+	}
+	head := githubv4.GitObjectID(headSHA)
+	input := EnqueuePullRequestInput{
+		PullRequestID:   q.Repository.PullRequest.ID,
+		ExpectedHeadOid: &head,
+	}
+	if err := m.v4client.Mutate(ctx, &mutation, input, nil); err != nil {
+		return "", errors.Wrapf(err, "failed to add %s to the merge queue", pullCtx.Locator())
+	}
+
+	logger.Info().Msgf("Added %s to the merge queue of %s at position %d", pullCtx.Locator(), pullCtx.BaseRef(), mutation.EnqueuePullRequest.MergeQueueEntry.Position)
+	return "", ErrEnqueued
+}
+
+// mergeWithoutQueue merges the pull request with the embedded Merger. If the
+// merge fails, the target branch may require a merge queue since it was
+// cached, so the next merge looks it up again.
+func (m *NativeQueueMerger) mergeWithoutQueue(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
+	sha, err := m.Merger.Merge(ctx, pullCtx, method, msg, headSHA)
+	if err != nil {
+		m.cache.setWithoutQueue(QueueKeyFor(pullCtx), false)
+	}
+	return sha, err
+}
+
+var mergeGroupRefPattern = regexp.MustCompile(`^(?:refs/heads/)?gh-readonly-queue/(.+)/pr-(\d+)-[0-9a-f]+$`)
+
+// ParseMergeGroupRef returns the target branch and the number of the pull
+// request of a merge group from the name of its branch, which GitHub formats
+// as "gh-readonly-queue/<target branch>/pr-<number>-<base SHA>".
+func ParseMergeGroupRef(ref string) (string, int, bool) {
+	m := mergeGroupRefPattern.FindStringSubmatch(ref)
+	if m == nil {
+		return "", 0, false
+	}
+
+	number, err := strconv.Atoi(m[2])
+	if err != nil {
+		return "", 0, false
+	}
+	return m[1], number, true
+}
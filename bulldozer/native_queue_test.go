@@ -0,0 +1,145 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+func TestNativeQueueMerger(t *testing.T) {
+	ctx := context.Background()
+	pullCtx := &pulltest.MockPullContext{
+		OwnerValue:   "owner",
+		RepoValue:    "repo",
+		NumberValue:  7,
+		BaseRefValue: "main",
+		HeadSHAValue: "abc",
+	}
+
+	const mutation = `{"data": {"enqueuePullRequest": {"mergeQueueEntry": {"position": 2}}}}`
+
+	t.Run("noMergeQueue", func(t *testing.T) {
+		srv := newGraphQLServer(t, `{"data": {"repository": {"pullRequest": {"id": "PR_1", "isInMergeQueue": false}, "mergeQueue": null}}}`, mutation)
+		defer srv.Close()
+
+		merger := &MockMerger{}
+		m := NewNativeQueueMerger(merger, srv.Client(), nil)
+
+		sha, err := m.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, "deadbeef", sha)
+		assert.Equal(t, 1, merger.MergeCount, "pull request is merged with the REST API")
+		assert.Empty(t, srv.Mutations)
+	})
+
+	t.Run("enqueue", func(t *testing.T) {
+		srv := newGraphQLServer(t, `{"data": {"repository": {"pullRequest": {"id": "PR_1", "isInMergeQueue": false}, "mergeQueue": {"id": "MQ_1"}}}}`, mutation)
+		defer srv.Close()
+
+		merger := &MockMerger{}
+		m := NewNativeQueueMerger(merger, srv.Client(), nil)
+
+		_, err := m.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+		assert.Equal(t, ErrEnqueued, err)
+		assert.Equal(t, 0, merger.MergeCount)
+		require.Len(t, srv.Mutations, 1)
+		assert.Equal(t, map[string]interface{}{"pullRequestId": "PR_1", "expectedHeadOid": "abc"}, srv.Mutations[0]["input"])
+	})
+
+	t.Run("alreadyQueued", func(t *testing.T) {
+		srv := newGraphQLServer(t, `{"data": {"repository": {"pullRequest": {"id": "PR_1", "isInMergeQueue": true}, "mergeQueue": {"id": "MQ_1"}}}}`, mutation)
+		defer srv.Close()
+
+		m := NewNativeQueueMerger(&MockMerger{}, srv.Client(), nil)
+
+		_, err := m.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+		assert.Equal(t, ErrEnqueued, err)
+		assert.Empty(t, srv.Mutations, "queued pull requests are not added again")
+	})
+
+	t.Run("cached", func(t *testing.T) {
+		srv := newGraphQLServer(t, `{"data": {"repository": {"pullRequest": {"id": "PR_1", "isInMergeQueue": false}, "mergeQueue": null}}}`, mutation)
+		defer srv.Close()
+
+		merger := &MockMerger{}
+		m := NewNativeQueueMerger(merger, srv.Client(), NewNativeQueueCache(DefaultNativeQueueCacheTTL))
+
+		for i := 0; i < 2; i++ {
+			_, err := m.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, merger.MergeCount)
+		assert.Equal(t, 1, srv.Queries, "branches without a merge queue are cached")
+
+		merger.MergeError = errors.New("merge rejected")
+		_, err := m.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+		require.Error(t, err)
+		assert.Equal(t, 1, srv.Queries)
+
+		merger.MergeError = nil
+		_, err = m.Merge(ctx, pullCtx, MergeCommit, CommitMessage{}, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, 2, srv.Queries, "failed merges look up the merge queue again")
+	})
+}
+
+func TestParseMergeGroupRef(t *testing.T) {
+	tests := map[string]struct {
+		Ref    string
+		Base   string
+		Number int
+		OK     bool
+	}{
+		"branch": {
+			Ref:    "refs/heads/gh-readonly-queue/main/pr-123-f6374a30ec7a3f2dbf35b40ac984b64358ccd246",
+			Base:   "main",
+			Number: 123,
+			OK:     true,
+		},
+		"nestedBranch": {
+			Ref:    "gh-readonly-queue/release/2.x/pr-7-89aec3244253260261351047f0bf6d9b7626c4f6",
+			Base:   "release/2.x",
+			Number: 7,
+			OK:     true,
+		},
+		// head_ref of a merge_group event of a group with several pull
+		// requests, which is named after the last one
+		"mergeGroupEvent": {
+			Ref:    "refs/heads/gh-readonly-queue/main/pr-4352-ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+			Base:   "main",
+			Number: 4352,
+			OK:     true,
+		},
+		"otherRef": {
+			Ref: "refs/heads/feature/pr-7-abc",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			base, number, ok := ParseMergeGroupRef(test.Ref)
+			assert.Equal(t, test.OK, ok)
+			assert.Equal(t, test.Base, base)
+			assert.Equal(t, test.Number, number)
+		})
+	}
+}
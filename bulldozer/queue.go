@@ -0,0 +1,387 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/pkg/errors"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+type QueueConfig struct {
+	// Enabled merges the pull requests targeting a branch one at a time, in
+	// the order they became ready. The first pull request is updated with
+	// the target branch and merged once its status checks pass again.
+	Enabled bool `yaml:"enabled"`
+
+	// BatchSize is the maximum number of queued pull requests that are
+	// tested together on the staging branch. Values below 2 merge pull
+	// requests one at a time.
+	BatchSize int `yaml:"batch_size"`
+}
+
+func (c QueueConfig) validate() error {
+	if c.BatchSize < 0 {
+		return errors.Errorf("batch_size must not be negative, got %d", c.BatchSize)
+	}
+	return nil
+}
+
+// QueueKey identifies the merge queue of a target branch.
+type QueueKey struct {
+	Owner string `json:"owner"`
+	Repo  string `json:"repo"`
+	Base  string `json:"base"`
+}
+
+func QueueKeyFor(pullCtx pull.Context) QueueKey {
+	return QueueKey{
+		Owner: pullCtx.Owner(),
+		Repo:  pullCtx.Repo(),
+		Base:  pullCtx.BaseRef(),
+	}
+}
+
+func (k QueueKey) String() string {
+	return fmt.Sprintf("%s/%s:%s", k.Owner, k.Repo, k.Base)
+}
+
+// QueueEntry is a pull request in a merge queue.
+type QueueEntry struct {
+	Number     int       `json:"number"`
+	EnqueuedAt time.Time `json:"enqueued_at"`
+
+	// State describes what the pull request is waiting for.
+	State string `json:"state"`
+}
+
+// QueueState is a snapshot of a merge queue.
+type QueueState struct {
+	QueueKey
+	Entries []QueueEntry `json:"entries"`
+	Batch   *Batch       `json:"batch,omitempty"`
+}
+
+// MergeQueue holds the merge queues of all target branches. Queues are kept
+// in memory, so each server instance has its own queues and they are lost
+// when the server restarts.
+type MergeQueue struct {
+	mu       sync.Mutex
+	queues   map[QueueKey][]QueueEntry
+	rejected map[QueueKey]map[int]rejection
+
+	// batch mode state, see batch.go
+	batches   map[QueueKey]*Batch
+	limits    map[QueueKey]int
+	nextBatch int
+}
+
+// rejection records why a pull request was removed from the queue. It
+// applies until the head of the pull request changes.
+type rejection struct {
+	sha    string
+	reason string
+}
+
+func NewMergeQueue() *MergeQueue {
+	return &MergeQueue{
+		queues:   make(map[QueueKey][]QueueEntry),
+		batches:  make(map[QueueKey]*Batch),
+		limits:   make(map[QueueKey]int),
+		rejected: make(map[QueueKey]map[int]rejection),
+	}
+}
+
+// Enqueue adds the pull request to the end of the queue, unless it is
+// already queued. It returns the position of the pull request, starting at 0
+// for the head of the queue.
+func (q *MergeQueue) Enqueue(key QueueKey, number int) int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if i := q.indexOf(key, number); i >= 0 {
+		return i
+	}
+	q.queues[key] = append(q.queues[key], QueueEntry{
+		Number:     number,
+		EnqueuedAt: time.Now(),
+		State:      "queued",
+	})
+	return len(q.queues[key]) - 1
+}
+
+// Position returns the position of the pull request in the queue and the
+// length of the queue. The position is -1 if the pull request is not queued.
+func (q *MergeQueue) Position(key QueueKey, number int) (int, int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.indexOf(key, number), len(q.queues[key])
+}
+
+// Remove removes the pull request from the queue. It returns the new head of
+// the queue if the pull request was at the head, so that the caller can
+// advance the queue.
+func (q *MergeQueue) Remove(key QueueKey, number int) (QueueEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return q.remove(key, number)
+}
+
+func (q *MergeQueue) remove(key QueueKey, number int) (QueueEntry, bool) {
+	i := q.indexOf(key, number)
+	if i < 0 {
+		return QueueEntry{}, false
+	}
+
+	// a batch that has not passed yet is no longer valid without the pull
+	// request, while a passed batch only waits for its remaining members
+	if b := q.batches[key]; b != nil && b.contains(number) {
+		if b.Passed && len(b.Numbers) > 1 {
+			b.without(number)
+		} else {
+			delete(q.batches, key)
+		}
+	}
+
+	entries := append(q.queues[key][:i:i], q.queues[key][i+1:]...)
+	if len(entries) == 0 {
+		delete(q.queues, key)
+		delete(q.batches, key)
+		delete(q.limits, key)
+		return QueueEntry{}, false
+	}
+	q.queues[key] = entries
+
+	if i != 0 {
+		return QueueEntry{}, false
+	}
+	return entries[0], true
+}
+
+// Reject removes the pull request from the queue and fails its merge queue
+// condition until its head changes. It returns the new head of the queue if
+// the pull request was at the head.
+func (q *MergeQueue) Reject(key QueueKey, number int, sha, reason string) (QueueEntry, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.rejected[key] == nil {
+		q.rejected[key] = make(map[int]rejection)
+	}
+	q.rejected[key][number] = rejection{sha: sha, reason: reason}
+
+	return q.remove(key, number)
+}
+
+// rejection returns the reason the pull request was rejected at the given
+// head, if it was.
+func (q *MergeQueue) rejection(key QueueKey, number int, sha string) (string, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	r, ok := q.rejected[key][number]
+	if !ok {
+		return "", false
+	}
+	if r.sha != sha {
+		delete(q.rejected[key], number)
+		return "", false
+	}
+	return r.reason, true
+}
+
+// SetState records what the queued pull request is waiting for.
+func (q *MergeQueue) SetState(key QueueKey, number int, state string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if i := q.indexOf(key, number); i >= 0 {
+		q.queues[key][i].State = state
+	}
+}
+
+// Snapshot returns the state of all non-empty queues, ordered by key.
+func (q *MergeQueue) Snapshot() []QueueState {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	states := make([]QueueState, 0, len(q.queues))
+	for key, entries := range q.queues {
+		states = append(states, QueueState{
+			QueueKey: key,
+			Entries:  append([]QueueEntry(nil), entries...),
+			Batch:    q.batches[key].copy(),
+		})
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].String() < states[j].String() })
+	return states
+}
+
+func (q *MergeQueue) indexOf(key QueueKey, number int) int {
+	for i, e := range q.queues[key] {
+		if e.Number == number {
+			return i
+		}
+	}
+	return -1
+}
+
+// IsBehind returns true if the target branch has commits that are missing
+// from the head of the pull request.
+func IsBehind(ctx context.Context, pullCtx pull.Context, client *github.Client) (bool, error) {
+	comparison, _, err := client.Repositories.CompareCommits(ctx, pullCtx.Owner(), pullCtx.Repo(), pullCtx.BaseRef(), pullCtx.HeadSHA(), nil)
+	if err != nil {
+		return false, errors.Wrapf(err, "cannot compare %s and %s for %q", pullCtx.BaseRef(), pullCtx.HeadSHA(), pullCtx.Locator())
+	}
+	return comparison.GetBehindBy() > 0, nil
+}
+
+// UpdateBranch merges the target branch into the head of the pull request.
+// Unlike UpdatePR, it returns once GitHub accepted the update, and also
+// updates pull requests from forks that allow edits from maintainers.
+func UpdateBranch(ctx context.Context, pullCtx pull.Context, client *github.Client) error {
+	opts := &github.PullRequestBranchUpdateOptions{
+		ExpectedHeadSHA: github.String(pullCtx.HeadSHA()),
+	}
+
+	_, _, err := client.PullRequests.UpdateBranch(ctx, pullCtx.Owner(), pullCtx.Repo(), pullCtx.Number(), opts)
+	if _, ok := err.(*github.AcceptedError); ok {
+		// GitHub updates the branch asynchronously
+		return nil
+	}
+	return errors.Wrapf(err, "failed to update branch of %q", pullCtx.Locator())
+}
+
+// EvaluateRejection fails the merge queue condition of a pull request that a
+// merge queue rejected at its current head.
+func EvaluateRejection(queue *MergeQueue, pullCtx pull.Context, decision *Decision) {
+	if reason, ok := queue.rejection(QueueKeyFor(pullCtx), pullCtx.Number(), pullCtx.HeadSHA()); ok {
+		decision.add("merge queue", Fail, reason)
+	}
+}
+
+// EvaluateQueue admits the pull request to the merge queue of its target
+// branch once all other conditions pass, and adds the merge queue condition
+// to the decision. Only the head of the queue passes the condition: it is
+// updated with the target branch while it is behind, and may merge once it is
+// up to date. Pull requests that fail are removed from the queue; if the
+// removed pull request was at the head, the new head is returned so that the
+// caller can evaluate it. EvaluateRejection must be called first, so that
+// rejected pull requests are not queued again.
+func EvaluateQueue(ctx context.Context, queue *MergeQueue, pullCtx pull.Context, client *github.Client, config MergeConfig, decision *Decision) (QueueEntry, bool, error) {
+	key := QueueKeyFor(pullCtx)
+	number := pullCtx.Number()
+
+	if decision.Result() == Fail {
+		next, advance := queue.Remove(key, number)
+		return next, advance, nil
+	}
+
+	position, length := queue.Position(key, number)
+	if position < 0 {
+		if decision.Result() != Pass {
+			return QueueEntry{}, false, nil
+		}
+		position = queue.Enqueue(key, number)
+		length = position + 1
+	}
+
+	if config.Queue.BatchSize > 1 {
+		return evaluateBatch(ctx, queue, pullCtx, client, config, decision)
+	}
+
+	if position > 0 {
+		decision.add("merge queue", Pending, fmt.Sprintf("pull request is at position %d of %d in the merge queue", position+1, length))
+		return QueueEntry{}, false, nil
+	}
+
+	behind, err := IsBehind(ctx, pullCtx, client)
+	if err != nil {
+		return QueueEntry{}, false, err
+	}
+	if behind {
+		if err := UpdateBranch(ctx, pullCtx, client); err != nil {
+			// rejecting the pull request keeps it from blocking the queue
+			// again until new commits are pushed to it
+			reason := fmt.Sprintf("pull request could not be updated with %s: %v", pullCtx.BaseRef(), errors.Cause(err))
+			decision.add("merge queue", Fail, reason)
+			next, advance := queue.Reject(key, number, pullCtx.HeadSHA(), reason)
+			return next, advance, nil
+		}
+		queue.SetState(key, number, "updating")
+		decision.add("merge queue", Pending, fmt.Sprintf("pull request is first in the merge queue and is being updated with %s", pullCtx.BaseRef()))
+		return QueueEntry{}, false, nil
+	}
+
+	if decision.Result() == Pass {
+		queue.SetState(key, number, "merging")
+	} else {
+		queue.SetState(key, number, "waiting")
+	}
+	decision.add("merge queue", Pass, fmt.Sprintf("pull request is first in the merge queue and up to date with %s", pullCtx.BaseRef()))
+	return QueueEntry{}, false, nil
+}
+
+// QueueMerger removes pull requests from the merge queue once they merged or
+// GitHub rejected the merge, and advances the queue. Pull requests whose head
+// changed since they were evaluated keep their place until they are
+// evaluated again.
+type QueueMerger struct {
+	Merger
+
+	queue   *MergeQueue
+	advance func(next QueueEntry)
+}
+
+// NewQueueMerger returns a Merger that removes pull requests from the queue
+// after merging them with another Merger. The advance function is called
+// with the new head of the queue, if any.
+func NewQueueMerger(merger Merger, queue *MergeQueue, advance func(next QueueEntry)) Merger {
+	return &QueueMerger{
+		Merger:  merger,
+		queue:   queue,
+		advance: advance,
+	}
+}
+
+func (m *QueueMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
+	sha, err := m.Merger.Merge(ctx, pullCtx, method, msg, headSHA)
+
+	// other errors are retried, so the pull request keeps its place
+	if err != nil {
+		gerr, ok := errors.Cause(err).(*github.ErrorResponse)
+		if !ok || gerr.Response == nil {
+			return sha, err
+		}
+		if gerr.Response.StatusCode != http.StatusMethodNotAllowed {
+			return sha, err
+		}
+	}
+
+	if next, ok := m.queue.Remove(QueueKeyFor(pullCtx), pullCtx.Number()); ok {
+		m.advance(next)
+	}
+	return sha, err
+}
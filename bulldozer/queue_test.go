@@ -0,0 +1,670 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+func TestMergeQueue(t *testing.T) {
+	queue := NewMergeQueue()
+	main := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+	release := QueueKey{Owner: "owner", Repo: "repo", Base: "release"}
+
+	assert.Equal(t, 0, queue.Enqueue(main, 1))
+	assert.Equal(t, 1, queue.Enqueue(main, 2))
+	assert.Equal(t, 2, queue.Enqueue(main, 3))
+	assert.Equal(t, 1, queue.Enqueue(main, 2), "pull request is queued once")
+	assert.Equal(t, 0, queue.Enqueue(release, 4))
+
+	pos, length := queue.Position(main, 3)
+	assert.Equal(t, 2, pos)
+	assert.Equal(t, 3, length)
+
+	pos, _ = queue.Position(release, 1)
+	assert.Equal(t, -1, pos)
+
+	_, advance := queue.Remove(main, 2)
+	assert.False(t, advance, "removing a queued pull request does not advance the queue")
+
+	next, advance := queue.Remove(main, 1)
+	require.True(t, advance, "removing the head advances the queue")
+	assert.Equal(t, 3, next.Number)
+
+	_, advance = queue.Remove(main, 1)
+	assert.False(t, advance, "removing an unknown pull request does nothing")
+
+	queue.SetState(main, 3, "merging")
+
+	states := queue.Snapshot()
+	require.Len(t, states, 2)
+	assert.Equal(t, main, states[0].QueueKey)
+	require.Len(t, states[0].Entries, 1)
+	assert.Equal(t, 3, states[0].Entries[0].Number)
+	assert.Equal(t, "merging", states[0].Entries[0].State)
+	assert.Equal(t, release, states[1].QueueKey)
+
+	_, advance = queue.Remove(release, 4)
+	assert.False(t, advance, "removing the last pull request does not advance the queue")
+	assert.Len(t, queue.Snapshot(), 1)
+}
+
+func TestEvaluateQueue(t *testing.T) {
+	ctx := context.Background()
+
+	newPullCtx := func(number int) *pulltest.MockPullContext {
+		return &pulltest.MockPullContext{
+			OwnerValue:   "owner",
+			RepoValue:    "repo",
+			NumberValue:  number,
+			BaseRefValue: "main",
+		}
+	}
+	key := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+
+	t.Run("notReady", func(t *testing.T) {
+		queue := NewMergeQueue()
+		decision := &Decision{}
+		decision.add("whitelist", Pending, "pull request is not whitelisted")
+
+		_, advance, err := EvaluateQueue(ctx, queue, newPullCtx(1), nil, MergeConfig{}, decision)
+		require.NoError(t, err)
+		assert.False(t, advance)
+		assert.Empty(t, queue.Snapshot(), "pull requests that are not ready are not queued")
+	})
+
+	t.Run("queued", func(t *testing.T) {
+		queue := NewMergeQueue()
+		queue.Enqueue(key, 1)
+
+		decision := &Decision{}
+		_, advance, err := EvaluateQueue(ctx, queue, newPullCtx(2), nil, MergeConfig{}, decision)
+		require.NoError(t, err)
+		assert.False(t, advance)
+
+		assert.Equal(t, Pending, decision.Result())
+		assert.Equal(t, []Condition{
+			{Name: "merge queue", Result: Pending, Reason: "pull request is at position 2 of 2 in the merge queue"},
+		}, decision.Conditions)
+	})
+
+	t.Run("failedHead", func(t *testing.T) {
+		queue := NewMergeQueue()
+		queue.Enqueue(key, 1)
+		queue.Enqueue(key, 2)
+
+		decision := &Decision{}
+		decision.add("blacklist", Fail, "pull request has a blacklist label")
+
+		next, advance, err := EvaluateQueue(ctx, queue, newPullCtx(1), nil, MergeConfig{}, decision)
+		require.NoError(t, err)
+		require.True(t, advance)
+		assert.Equal(t, 2, next.Number)
+
+		pos, _ := queue.Position(key, 1)
+		assert.Equal(t, -1, pos, "failed pull request is removed from the queue")
+	})
+
+	t.Run("updateFailed", func(t *testing.T) {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/repos/owner/repo/compare/main...a", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"behind_by": 1}`)
+		})
+		mux.HandleFunc("/repos/owner/repo/pulls/1/update-branch", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			fmt.Fprint(w, `{"message": "merge conflict between base and head"}`)
+		})
+		srv := httptest.NewServer(mux)
+		defer srv.Close()
+
+		client := github.NewClient(nil)
+		client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+		queue := NewMergeQueue()
+		queue.Enqueue(key, 1)
+		queue.Enqueue(key, 2)
+
+		pullCtx := newPullCtx(1)
+		pullCtx.HeadSHAValue = "a"
+
+		decision := &Decision{}
+		next, advance, err := EvaluateQueue(ctx, queue, pullCtx, client, MergeConfig{}, decision)
+		require.NoError(t, err)
+		require.True(t, advance)
+		assert.Equal(t, 2, next.Number)
+		assert.Equal(t, Fail, decision.Result())
+
+		decision = &Decision{}
+		EvaluateRejection(queue, pullCtx, decision)
+		assert.Equal(t, Fail, decision.Result(), "pull request that cannot be updated is rejected until it changes")
+
+		pullCtx.HeadSHAValue = "b"
+		decision = &Decision{}
+		EvaluateRejection(queue, pullCtx, decision)
+		assert.Empty(t, decision.Conditions, "rejection does not apply to a new head")
+	})
+}
+
+func TestMergeQueueBatches(t *testing.T) {
+	queue := NewMergeQueue()
+	key := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+	for n := 1; n <= 5; n++ {
+		queue.Enqueue(key, n)
+	}
+
+	batch, started := queue.startBatch(key, 4)
+	require.True(t, started)
+	assert.Equal(t, []int{1, 2, 3, 4}, batch.Numbers)
+
+	_, started = queue.startBatch(key, 4)
+	assert.False(t, started, "only one batch is tested at a time")
+
+	queue.stageBatch(key, batch.id, []int{1, 2, 3, 4}, []string{"a", "b", "c", "d"}, "base", "staging")
+	_, head, ok := queue.BatchForCommit("owner", "repo", "staging")
+	require.True(t, ok)
+	assert.Equal(t, 1, head.Number)
+
+	_, _, isolated := queue.failBatch(key, batch.id)
+	assert.False(t, isolated, "failed batches with several pull requests are split")
+
+	batch, started = queue.startBatch(key, 4)
+	require.True(t, started)
+	assert.Equal(t, []int{1, 2}, batch.Numbers, "batch is halved after a failure")
+	queue.cancelBatch(key, batch.id)
+
+	batch, _ = queue.startBatch(key, 4)
+	assert.Equal(t, []int{1, 2}, batch.Numbers, "cancelled batches keep the limit")
+	queue.stageBatch(key, batch.id, []int{1, 2}, []string{"a", "b"}, "base", "staging")
+	queue.failBatch(key, batch.id)
+
+	batch, _ = queue.startBatch(key, 4)
+	require.Equal(t, []int{1}, batch.Numbers)
+	queue.stageBatch(key, batch.id, []int{1}, []string{"a"}, "base", "staging")
+
+	culprit, sha, isolated := queue.failBatch(key, batch.id)
+	require.True(t, isolated)
+	assert.Equal(t, 1, culprit)
+	assert.Equal(t, "a", sha)
+
+	next, advance := queue.Reject(key, culprit, sha, "failed")
+	require.True(t, advance)
+	assert.Equal(t, 2, next.Number)
+
+	reason, ok := queue.rejection(key, 1, "a")
+	assert.True(t, ok)
+	assert.Equal(t, "failed", reason)
+
+	_, ok = queue.rejection(key, 1, "e")
+	assert.False(t, ok, "rejection does not apply to a new head")
+
+	batch, _ = queue.startBatch(key, 4)
+	assert.Equal(t, []int{2, 3, 4, 5}, batch.Numbers, "limit is reset after isolating a failure")
+	queue.stageBatch(key, batch.id, []int{2, 3}, []string{"b", "c"}, "base", "staging")
+	queue.passBatch(key, batch.id)
+
+	_, advance = queue.Remove(key, 2)
+	require.True(t, advance)
+	batch, started = queue.startBatch(key, 4)
+	assert.False(t, started, "passed batch waits for its remaining pull requests")
+	assert.Equal(t, []int{3}, batch.Numbers)
+
+	next, advance = queue.completeBatch(key, batch.id)
+	require.True(t, advance)
+	assert.Equal(t, 4, next.Number)
+}
+
+// repositoryServer fakes the parts of the REST API of a repository that the
+// merge queue uses in batch mode.
+type repositoryServer struct {
+	*httptest.Server
+
+	refs     map[string]string
+	heads    map[int]string
+	closed   map[int]bool
+	statuses map[string]string
+
+	conflicts          map[string]bool
+	refuseFastForward  bool
+	fastForwardRefused bool
+}
+
+func newRepositoryServer(t *testing.T) *repositoryServer {
+	s := &repositoryServer{
+		refs:      map[string]string{"main": "base"},
+		heads:     map[int]string{1: "a", 2: "b", 3: "c"},
+		closed:    make(map[int]bool),
+		statuses:  make(map[string]string),
+		conflicts: make(map[string]bool),
+	}
+
+	const prefix = "/repos/owner/repo/"
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := strings.TrimPrefix(r.URL.Path, prefix)
+		w.Header().Set("Content-Type", "application/json")
+
+		switch {
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "git/ref/heads/"):
+			sha, ok := s.refs[strings.TrimPrefix(path, "git/ref/heads/")]
+			if !ok {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprintf(w, `{"ref": "refs/%s", "object": {"sha": %q}}`, strings.TrimPrefix(path, "git/ref/"), sha)
+
+		case r.Method == http.MethodPatch && strings.HasPrefix(path, "git/refs/heads/"):
+			branch := strings.TrimPrefix(path, "git/refs/heads/")
+			var body struct{ SHA string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+			if _, ok := s.refs[branch]; !ok {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message": "Reference does not exist"}`)
+				return
+			}
+			if branch == "main" && s.refuseFastForward {
+				s.fastForwardRefused = true
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"message": "Protected branch update failed"}`)
+				return
+			}
+			s.refs[branch] = body.SHA
+			fmt.Fprintf(w, `{"ref": "refs/heads/%s", "object": {"sha": %q}}`, branch, body.SHA)
+
+		case r.Method == http.MethodPost && path == "git/refs":
+			var body struct{ Ref, SHA string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+			s.refs[strings.TrimPrefix(body.Ref, "refs/heads/")] = body.SHA
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"ref": %q, "object": {"sha": %q}}`, body.Ref, body.SHA)
+
+		case r.Method == http.MethodDelete && strings.HasPrefix(path, "git/refs/heads/"):
+			delete(s.refs, strings.TrimPrefix(path, "git/refs/heads/"))
+			w.WriteHeader(http.StatusNoContent)
+
+		case r.Method == http.MethodPost && path == "merges":
+			var body struct{ Base, Head string }
+			require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+			if s.conflicts[body.Head] {
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"message": "Merge conflict"}`)
+				return
+			}
+			s.refs[body.Base] += "+" + body.Head
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"sha": %q}`, s.refs[body.Base])
+
+		case r.Method == http.MethodGet && strings.HasPrefix(path, "pulls/"):
+			number, err := strconv.Atoi(strings.TrimPrefix(path, "pulls/"))
+			require.NoError(t, err)
+
+			state := "open"
+			if s.closed[number] {
+				state = "closed"
+			}
+			fmt.Fprintf(w, `{"number": %d, "state": %q, "head": {"sha": %q, "label": "owner:pr-%d"}, "base": {"ref": "main", "repo": {"name": "repo", "owner": {"login": "owner"}}}}`, number, state, s.heads[number], number)
+
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/statuses"):
+			sha := strings.TrimSuffix(strings.TrimPrefix(path, "commits/"), "/statuses")
+			if state, ok := s.statuses[sha]; ok {
+				fmt.Fprintf(w, `[{"id": 1, "context": "ci", "state": %q}]`, state)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+
+		case r.Method == http.MethodGet && strings.HasSuffix(path, "/check-runs"):
+			fmt.Fprint(w, `{"total_count": 0, "check_runs": []}`)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return s
+}
+
+func (s *repositoryServer) Client() *github.Client {
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(s.URL + "/")
+	return client
+}
+
+func TestBuildBatch(t *testing.T) {
+	ctx := context.Background()
+	key := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+	staging := StagingBranch("main")
+
+	newQueue := func() *MergeQueue {
+		queue := NewMergeQueue()
+		for n := 1; n <= 3; n++ {
+			queue.Enqueue(key, n)
+		}
+		return queue
+	}
+
+	t.Run("createsStaging", func(t *testing.T) {
+		srv := newRepositoryServer(t)
+		defer srv.Close()
+
+		queue := newQueue()
+		batch, _ := queue.startBatch(key, 2)
+		require.NoError(t, buildBatch(ctx, queue, srv.Client(), key, batch))
+
+		batch = queue.batch(key, batch.id)
+		require.NotNil(t, batch)
+		assert.Equal(t, []int{1, 2}, batch.Numbers)
+		assert.Equal(t, []string{"a", "b"}, batch.heads)
+		assert.Equal(t, "base", batch.BaseSHA)
+		assert.Equal(t, "base+a+b", batch.SHA)
+		assert.Equal(t, "base+a+b", srv.refs[staging])
+	})
+
+	t.Run("resetsStaging", func(t *testing.T) {
+		srv := newRepositoryServer(t)
+		defer srv.Close()
+		srv.refs[staging] = "stale"
+
+		queue := newQueue()
+		batch, _ := queue.startBatch(key, 2)
+		require.NoError(t, buildBatch(ctx, queue, srv.Client(), key, batch))
+
+		assert.Equal(t, "base+a+b", srv.refs[staging], "staging branch is reset to the target branch")
+	})
+
+	t.Run("rejectsConflicts", func(t *testing.T) {
+		srv := newRepositoryServer(t)
+		defer srv.Close()
+		srv.conflicts["b"] = true
+
+		queue := newQueue()
+		batch, _ := queue.startBatch(key, 3)
+		require.NoError(t, buildBatch(ctx, queue, srv.Client(), key, batch))
+
+		batch = queue.batch(key, batch.id)
+		require.NotNil(t, batch)
+		assert.Equal(t, []int{1, 3}, batch.Numbers)
+		assert.Equal(t, "base+a+c", batch.SHA)
+
+		_, ok := queue.rejection(key, 2, "b")
+		assert.True(t, ok, "conflicting pull request is rejected")
+		pos, _ := queue.Position(key, 2)
+		assert.Equal(t, -1, pos)
+	})
+}
+
+func TestFastForwardBatch(t *testing.T) {
+	ctx := context.Background()
+	key := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+
+	newQueue := func() (*MergeQueue, *Batch) {
+		queue := NewMergeQueue()
+		for n := 1; n <= 3; n++ {
+			queue.Enqueue(key, n)
+		}
+		batch, _ := queue.startBatch(key, 2)
+		queue.stageBatch(key, batch.id, []int{1, 2}, []string{"a", "b"}, "base", "base+a+b")
+		return queue, queue.batch(key, batch.id)
+	}
+
+	t.Run("baseMoved", func(t *testing.T) {
+		srv := newRepositoryServer(t)
+		defer srv.Close()
+		srv.refs["main"] = "moved"
+
+		queue, batch := newQueue()
+		decision := &Decision{}
+		next, advance, err := fastForwardBatch(ctx, queue, srv.Client(), key, 1, batch, decision)
+		require.NoError(t, err)
+		require.True(t, advance)
+		assert.Equal(t, 1, next.Number)
+
+		assert.Equal(t, Pending, decision.Result())
+		assert.Nil(t, queue.batch(key, batch.id), "batch is rebuilt")
+		assert.Equal(t, "moved", srv.refs["main"])
+	})
+
+	t.Run("refused", func(t *testing.T) {
+		srv := newRepositoryServer(t)
+		defer srv.Close()
+		srv.refuseFastForward = true
+
+		queue, batch := newQueue()
+		decision := &Decision{}
+		_, advance, err := fastForwardBatch(ctx, queue, srv.Client(), key, 1, batch, decision)
+		require.NoError(t, err)
+		assert.False(t, advance)
+
+		assert.True(t, srv.fastForwardRefused)
+		assert.Equal(t, Pass, decision.Result(), "head of the queue is merged on its own")
+		require.NotNil(t, queue.batch(key, batch.id))
+		assert.True(t, queue.batch(key, batch.id).Passed)
+
+		decision = &Decision{}
+		mergeBatchMember(queue, key, 2, batch, decision)
+		assert.Equal(t, Pending, decision.Result(), "other pull requests wait for their turn")
+	})
+
+	t.Run("fastForwarded", func(t *testing.T) {
+		srv := newRepositoryServer(t)
+		defer srv.Close()
+
+		queue, batch := newQueue()
+		decision := &Decision{}
+		next, advance, err := fastForwardBatch(ctx, queue, srv.Client(), key, 1, batch, decision)
+		require.NoError(t, err)
+		require.True(t, advance)
+		assert.Equal(t, 3, next.Number)
+
+		assert.Equal(t, Pending, decision.Result(), "merged pull requests are not merged again")
+		assert.Equal(t, &MergedBatch{Numbers: []int{1, 2}, SHA: "base+a+b"}, decision.MergedBatch)
+		assert.Equal(t, "base+a+b", srv.refs["main"])
+		pos, length := queue.Position(key, 3)
+		assert.Equal(t, 0, pos)
+		assert.Equal(t, 1, length, "merged pull requests leave the queue")
+	})
+}
+
+func TestEvaluateBatch(t *testing.T) {
+	ctx := context.Background()
+	key := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+	config := MergeConfig{
+		RequiredStatuses: []RequiredStatus{{Name: "ci"}},
+		Queue:            QueueConfig{Enabled: true, BatchSize: 2},
+	}
+	pullCtx := &pulltest.MockPullContext{
+		OwnerValue:   "owner",
+		RepoValue:    "repo",
+		NumberValue:  1,
+		BaseRefValue: "main",
+		HeadSHAValue: "a",
+	}
+
+	newQueue := func(numbers ...int) (*MergeQueue, *Batch) {
+		queue := NewMergeQueue()
+		for n := 1; n <= 3; n++ {
+			queue.Enqueue(key, n)
+		}
+		batch, _ := queue.startBatch(key, len(numbers))
+		heads := map[int]string{1: "a", 2: "b"}
+		var shas []string
+		for _, n := range numbers {
+			shas = append(shas, heads[n])
+		}
+		queue.stageBatch(key, batch.id, numbers, shas, "base", "staged")
+		return queue, queue.batch(key, batch.id)
+	}
+
+	newServer := func(stagingState string) *repositoryServer {
+		srv := newRepositoryServer(t)
+		srv.statuses["staged"] = stagingState
+		srv.statuses["b"] = "success"
+		return srv
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		srv := newServer("pending")
+		defer srv.Close()
+
+		queue, batch := newQueue(1, 2)
+		decision := &Decision{}
+		_, advance, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+		assert.False(t, advance)
+
+		assert.Equal(t, []Condition{
+			{Name: "merge queue", Result: Pending, Reason: "batch of 2 pull requests is waiting for required statuses on bulldozer/staging/main"},
+		}, decision.Conditions)
+		assert.NotNil(t, queue.batch(key, batch.id))
+	})
+
+	t.Run("failedBatchIsSplit", func(t *testing.T) {
+		srv := newServer("failure")
+		defer srv.Close()
+
+		queue, batch := newQueue(1, 2)
+		decision := &Decision{}
+		_, _, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+
+		assert.Equal(t, Pending, decision.Result())
+		assert.Nil(t, queue.batch(key, batch.id))
+		pos, _ := queue.Position(key, 1)
+		assert.Equal(t, 0, pos, "pull request stays in the queue while the batch is split")
+	})
+
+	t.Run("failedPullRequestIsRejected", func(t *testing.T) {
+		srv := newServer("failure")
+		defer srv.Close()
+
+		queue, _ := newQueue(1)
+		decision := &Decision{}
+		next, advance, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+		require.True(t, advance)
+		assert.Equal(t, 2, next.Number)
+
+		assert.Equal(t, Fail, decision.Result())
+		_, ok := queue.rejection(key, 1, "a")
+		assert.True(t, ok)
+	})
+
+	t.Run("waitsForPullRequest", func(t *testing.T) {
+		srv := newServer("success")
+		defer srv.Close()
+
+		queue, batch := newQueue(1, 2)
+		decision := &Decision{}
+		decision.add("required reviews", Pending, "pull request is missing a review")
+		_, advance, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+		assert.False(t, advance)
+
+		assert.Equal(t, Pending, decision.Result())
+		assert.Equal(t, "base", srv.refs["main"], "target branch is not fast-forwarded")
+		assert.NotNil(t, queue.batch(key, batch.id))
+	})
+
+	t.Run("waitsForOtherPullRequest", func(t *testing.T) {
+		srv := newServer("success")
+		defer srv.Close()
+		srv.statuses["b"] = "pending"
+
+		queue, batch := newQueue(1, 2)
+		decision := &Decision{}
+		_, advance, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+		assert.False(t, advance)
+
+		assert.Equal(t, []Condition{
+			{Name: "merge queue", Result: Pending, Reason: "batch passed on bulldozer/staging/main and is waiting for pull request #2 to satisfy its merge conditions"},
+		}, decision.Conditions)
+		assert.Equal(t, "base", srv.refs["main"], "target branch is not fast-forwarded")
+		assert.NotNil(t, queue.batch(key, batch.id))
+	})
+
+	t.Run("rebuildsChangedBatch", func(t *testing.T) {
+		srv := newServer("success")
+		defer srv.Close()
+		srv.heads[2] = "b2"
+
+		queue, batch := newQueue(1, 2)
+		decision := &Decision{}
+		_, _, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+
+		assert.Equal(t, []Condition{
+			{Name: "merge queue", Result: Pending, Reason: "pull request #2 changed while the batch was tested, the batch is being rebuilt"},
+		}, decision.Conditions)
+		assert.Equal(t, "base", srv.refs["main"], "target branch is not fast-forwarded")
+		assert.Nil(t, queue.batch(key, batch.id))
+		pos, _ := queue.Position(key, 2)
+		assert.Equal(t, 1, pos, "changed pull request stays in the queue")
+	})
+
+	t.Run("fastForwarded", func(t *testing.T) {
+		srv := newServer("success")
+		defer srv.Close()
+
+		queue, _ := newQueue(1, 2)
+		decision := &Decision{}
+		next, advance, err := EvaluateQueue(ctx, queue, pullCtx, srv.Client(), config, decision)
+		require.NoError(t, err)
+		require.True(t, advance)
+		assert.Equal(t, 3, next.Number)
+
+		assert.Equal(t, &MergedBatch{Numbers: []int{1, 2}, SHA: "staged"}, decision.MergedBatch)
+		assert.Equal(t, "staged", srv.refs["main"])
+	})
+}
+
+func TestEvaluateQueueRejected(t *testing.T) {
+	ctx := context.Background()
+
+	queue := NewMergeQueue()
+	key := QueueKey{Owner: "owner", Repo: "repo", Base: "main"}
+	queue.Enqueue(key, 1)
+	queue.Reject(key, 1, "abc", "pull request failed on bulldozer/staging/main")
+
+	pullCtx := &pulltest.MockPullContext{
+		OwnerValue:   "owner",
+		RepoValue:    "repo",
+		NumberValue:  1,
+		BaseRefValue: "main",
+		HeadSHAValue: "abc",
+	}
+
+	decision := &Decision{}
+	EvaluateRejection(queue, pullCtx, decision)
+	_, _, err := EvaluateQueue(ctx, queue, pullCtx, nil, MergeConfig{}, decision)
+	require.NoError(t, err)
+	assert.Equal(t, []Condition{
+		{Name: "merge queue", Result: Fail, Reason: "pull request failed on bulldozer/staging/main"},
+	}, decision.Conditions)
+	assert.Empty(t, queue.Snapshot(), "rejected pull requests are not queued again")
+}
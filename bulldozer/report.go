@@ -0,0 +1,370 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+const (
+	// ReportNone disables reporting on pull requests.
+	ReportNone = "none"
+
+	// ReportCheckRun reports using a check run on the head commit.
+	ReportCheckRun = "check_run"
+
+	// ReportComment reports using a single comment on the pull request that
+	// is edited in place.
+	ReportComment = "comment"
+
+	// StatusCommentMarker identifies the comments created by CommentReporter.
+	// Comments containing it never trigger comment signals.
+	StatusCommentMarker = "<!-- bulldozer:status -->"
+)
+
+// Report describes the evaluation of a pull request for its authors and
+// reviewers.
+type Report struct {
+	Update *Decision
+	Merge  *Decision
+
+	// MergeAttempt is the outcome of the latest merge attempt, if bulldozer
+	// tried to merge the pull request.
+	MergeAttempt *MergeAttempt
+}
+
+type MergeAttempt struct {
+	Merged bool
+	SHA    string
+
+	// Enqueued is true if the pull request was added to a merge queue
+	// instead of being merged.
+	Enqueued bool
+
+	// AutoMerge is true if auto-merge was enabled on the pull request
+	// instead of merging it.
+	AutoMerge bool
+
+	// Reason explains why the merge failed.
+	Reason string
+}
+
+// Reporter publishes reports on pull requests.
+type Reporter interface {
+	Report(ctx context.Context, pullCtx pull.Context, report Report) error
+}
+
+// NopReporter discards all reports.
+type NopReporter struct{}
+
+func (NopReporter) Report(ctx context.Context, pullCtx pull.Context, report Report) error {
+	return nil
+}
+
+// Headline returns a single line summary of the report.
+func (r *Report) Headline() string {
+	switch {
+	case r.MergeAttempt != nil && r.MergeAttempt.Merged:
+		return fmt.Sprintf("Merged as %s", shortSHA(r.MergeAttempt.SHA))
+	case r.MergeAttempt != nil && r.MergeAttempt.Enqueued:
+		return "Added to the merge queue"
+	case r.MergeAttempt != nil && r.MergeAttempt.AutoMerge:
+		return "Auto-merge enabled"
+	case r.MergeAttempt != nil:
+		return "Merge failed"
+	case r.Merge == nil:
+		return "Merge conditions were not evaluated"
+	}
+
+	switch r.Merge.Result() {
+	case Pass:
+		return "Merging"
+	case Fail:
+		var failed string
+		for _, c := range r.Merge.Conditions {
+			if c.Result == Fail {
+				failed = c.Name
+				break
+			}
+		}
+		return fmt.Sprintf("Not merging because of the %s", failed)
+	default:
+		var names []string
+		for _, c := range r.Merge.Blocking() {
+			names = append(names, c.Name)
+		}
+		return fmt.Sprintf("Waiting for %s", strings.Join(names, ", "))
+	}
+}
+
+// Summary returns a Markdown description of the report.
+func (r *Report) Summary() string {
+	var b strings.Builder
+
+	if r.MergeAttempt != nil {
+		if r.MergeAttempt.Merged {
+			fmt.Fprintf(&b, "Bulldozer merged the pull request as %s.\n\n", r.MergeAttempt.SHA)
+		} else if r.MergeAttempt.Enqueued {
+			b.WriteString("Bulldozer added the pull request to the merge queue of the target branch.\n\n")
+		} else if r.MergeAttempt.AutoMerge {
+			b.WriteString("Bulldozer enabled auto-merge, GitHub merges the pull request once its requirements are met.\n\n")
+		} else {
+			fmt.Fprintf(&b, "Bulldozer tried to merge the pull request, but the merge failed: %s\n\n", markdownEscape(r.MergeAttempt.Reason))
+		}
+	}
+
+	writeDecision(&b, "Merge", r.Merge)
+	writeDecision(&b, "Update", r.Update)
+	return strings.TrimSpace(b.String())
+}
+
+func writeDecision(b *strings.Builder, title string, d *Decision) {
+	if d == nil {
+		return
+	}
+
+	fmt.Fprintf(b, "### %s: %s\n\n", title, d.Result())
+	if len(d.Conditions) == 0 {
+		b.WriteString("No conditions were evaluated.\n\n")
+		return
+	}
+
+	b.WriteString("| Condition | Result | Reason |\n")
+	b.WriteString("| --- | --- | --- |\n")
+	for _, c := range d.Conditions {
+		fmt.Fprintf(b, "| %s | %s | %s |\n", markdownEscape(c.Name), c.Result, markdownEscape(c.Reason))
+	}
+	b.WriteString("\n")
+}
+
+func markdownEscape(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+// ReportingMerger reports the outcome of merges performed by another Merger.
+type ReportingMerger struct {
+	Merger
+
+	reporter Reporter
+	report   Report
+}
+
+// NewReportingMerger returns a Merger that adds the outcome of each merge
+// attempt to the report and publishes it using the reporter.
+func NewReportingMerger(merger Merger, reporter Reporter, report Report) Merger {
+	return &ReportingMerger{
+		Merger:   merger,
+		reporter: reporter,
+		report:   report,
+	}
+}
+
+func (m *ReportingMerger) Merge(ctx context.Context, pullCtx pull.Context, method MergeMethod, msg CommitMessage, headSHA string) (string, error) {
+	sha, err := m.Merger.Merge(ctx, pullCtx, method, msg, headSHA)
+
+	m.report.MergeAttempt = newMergeAttempt(sha, err)
+	if rerr := m.reporter.Report(ctx, pullCtx, m.report); rerr != nil {
+		zerolog.Ctx(ctx).Error().Err(rerr).Msgf("Failed to report merge attempt for %q", pullCtx.Locator())
+	}
+
+	return sha, err
+}
+
+// newMergeAttempt returns the outcome of a call to Merger.Merge.
+func newMergeAttempt(sha string, err error) *MergeAttempt {
+	attempt := &MergeAttempt{Merged: err == nil, SHA: sha}
+	if errors.Cause(err) == ErrEnqueued {
+		attempt.Enqueued = true
+	} else if err != nil {
+		if gerr, ok := errors.Cause(err).(*github.ErrorResponse); ok && gerr.Response != nil {
+			attempt.Reason = fmt.Sprintf("GitHub responded with status %d: %s", gerr.Response.StatusCode, gerr.Message)
+		} else {
+			attempt.Reason = err.Error()
+		}
+	}
+	return attempt
+}
+
+// CheckRunReporter reports using a check run on the head commit of pull
+// requests. The check run is updated in place while the head commit does not
+// change.
+type CheckRunReporter struct {
+	client *github.Client
+	appID  int64
+	name   string
+}
+
+// NewCheckRunReporter returns a Reporter that manages check runs with the
+// given name. The appID identifies the check runs created by this reporter.
+func NewCheckRunReporter(client *github.Client, appID int64, name string) Reporter {
+	return &CheckRunReporter{
+		client: client,
+		appID:  appID,
+		name:   name,
+	}
+}
+
+func (r *CheckRunReporter) Report(ctx context.Context, pullCtx pull.Context, report Report) error {
+	status, conclusion := "in_progress", ""
+	switch {
+	case report.MergeAttempt != nil && report.MergeAttempt.Merged:
+		status, conclusion = "completed", "success"
+	case report.MergeAttempt != nil && (report.MergeAttempt.Enqueued || report.MergeAttempt.AutoMerge):
+		// GitHub merges the pull request later
+	case report.MergeAttempt != nil:
+		status, conclusion = "completed", "neutral"
+	case report.Merge == nil || report.Merge.Result() == Fail:
+		status, conclusion = "completed", "neutral"
+	}
+
+	output := &github.CheckRunOutput{
+		Title:   github.String(report.Headline()),
+		Summary: github.String(report.Summary()),
+	}
+
+	existing, err := r.findCheckRun(ctx, pullCtx)
+	if err != nil {
+		return err
+	}
+
+	// completed check runs cannot return to progress, so a new check run
+	// replaces them
+	if existing != nil && (existing.GetStatus() != "completed" || status == "completed") {
+		opts := github.UpdateCheckRunOptions{
+			Name:   r.name,
+			Status: github.String(status),
+			Output: output,
+		}
+		if conclusion != "" {
+			opts.Conclusion = github.String(conclusion)
+		}
+
+		_, _, err := r.client.Checks.UpdateCheckRun(ctx, pullCtx.Owner(), pullCtx.Repo(), existing.GetID(), opts)
+		return errors.Wrapf(err, "failed to update check run %d", existing.GetID())
+	}
+
+	opts := github.CreateCheckRunOptions{
+		Name:    r.name,
+		HeadSHA: pullCtx.HeadSHA(),
+		Status:  github.String(status),
+		Output:  output,
+	}
+	if conclusion != "" {
+		opts.Conclusion = github.String(conclusion)
+	}
+
+	_, _, err = r.client.Checks.CreateCheckRun(ctx, pullCtx.Owner(), pullCtx.Repo(), opts)
+	return errors.Wrap(err, "failed to create check run")
+}
+
+func (r *CheckRunReporter) findCheckRun(ctx context.Context, pullCtx pull.Context) (*github.CheckRun, error) {
+	opts := &github.ListCheckRunsOptions{
+		CheckName: github.String(r.name),
+		Filter:    github.String("latest"),
+	}
+	if r.appID != 0 {
+		opts.AppID = github.Int64(r.appID)
+	}
+
+	runs, _, err := r.client.Checks.ListCheckRunsForRef(ctx, pullCtx.Owner(), pullCtx.Repo(), pullCtx.HeadSHA(), opts)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list check runs for SHA %s", pullCtx.HeadSHA())
+	}
+
+	var latest *github.CheckRun
+	for _, run := range runs.CheckRuns {
+		if latest == nil || run.GetID() > latest.GetID() {
+			latest = run
+		}
+	}
+	return latest, nil
+}
+
+// CommentReporter reports using a single comment on pull requests, which is
+// edited in place as the evaluation changes.
+type CommentReporter struct {
+	client *github.Client
+}
+
+func NewCommentReporter(client *github.Client) Reporter {
+	return &CommentReporter{
+		client: client,
+	}
+}
+
+func (r *CommentReporter) Report(ctx context.Context, pullCtx pull.Context, report Report) error {
+	body := fmt.Sprintf("%s\n**bulldozer:** %s\n\n%s", StatusCommentMarker, report.Headline(), report.Summary())
+
+	existing, err := r.findComment(ctx, pullCtx)
+	if err != nil {
+		return err
+	}
+
+	if existing == nil {
+		_, _, err := r.client.Issues.CreateComment(ctx, pullCtx.Owner(), pullCtx.Repo(), pullCtx.Number(), &github.IssueComment{Body: github.String(body)})
+		return errors.Wrap(err, "failed to create status comment")
+	}
+
+	// avoid notifying subscribers and triggering events if nothing changed
+	if existing.GetBody() == body {
+		return nil
+	}
+
+	_, _, err = r.client.Issues.EditComment(ctx, pullCtx.Owner(), pullCtx.Repo(), existing.GetID(), &github.IssueComment{Body: github.String(body)})
+	return errors.Wrapf(err, "failed to edit status comment %d", existing.GetID())
+}
+
+func (r *CommentReporter) findComment(ctx context.Context, pullCtx pull.Context) (*github.IssueComment, error) {
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		comments, res, err := r.client.Issues.ListComments(ctx, pullCtx.Owner(), pullCtx.Repo(), pullCtx.Number(), opts)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to list issue comments")
+		}
+
+		for _, c := range comments {
+			if IsStatusComment(c.GetBody()) && c.GetUser().GetType() == "Bot" {
+				return c, nil
+			}
+		}
+
+		if res.NextPage == 0 {
+			return nil, nil
+		}
+		opts.Page = res.NextPage
+	}
+}
+
+// IsStatusComment returns true if the comment body was written by a
+// CommentReporter.
+func IsStatusComment(body string) bool {
+	return strings.Contains(body, StatusCommentMarker)
+}
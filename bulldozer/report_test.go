@@ -0,0 +1,150 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull"
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+type MockReporter struct {
+	Reports []Report
+}
+
+func (r *MockReporter) Report(ctx context.Context, pullCtx pull.Context, report Report) error {
+	r.Reports = append(r.Reports, report)
+	return nil
+}
+
+func TestReportHeadline(t *testing.T) {
+	tests := map[string]struct {
+		Report   Report
+		Headline string
+	}{
+		"merged": {
+			Report:   Report{MergeAttempt: &MergeAttempt{Merged: true, SHA: "f6374a30ec7a3f2dbf35b40ac984b64358ccd246"}},
+			Headline: "Merged as f6374a3",
+		},
+		"enqueued": {
+			Report:   Report{MergeAttempt: &MergeAttempt{Enqueued: true}},
+			Headline: "Added to the merge queue",
+		},
+		"mergeFailed": {
+			Report:   Report{MergeAttempt: &MergeAttempt{Reason: "GitHub responded with status 405: Required reviews"}},
+			Headline: "Merge failed",
+		},
+		"notEvaluated": {
+			Report:   Report{},
+			Headline: "Merge conditions were not evaluated",
+		},
+		"merging": {
+			Report:   Report{Merge: &Decision{Conditions: []Condition{{Name: "whitelist", Result: Pass}}}},
+			Headline: "Merging",
+		},
+		"failed": {
+			Report: Report{Merge: &Decision{Conditions: []Condition{
+				{Name: "blacklist", Result: Pass},
+				{Name: "whitelist", Result: Fail},
+			}}},
+			Headline: "Not merging because of the whitelist",
+		},
+		"pending": {
+			Report: Report{Merge: &Decision{Conditions: []Condition{
+				{Name: "whitelist", Result: Pass},
+				{Name: `status "ci"`, Result: Pending},
+				{Name: "required reviews", Result: Pending},
+			}}},
+			Headline: `Waiting for status "ci", required reviews`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Headline, test.Report.Headline())
+		})
+	}
+}
+
+func TestReportSummary(t *testing.T) {
+	report := Report{
+		Merge: &Decision{Conditions: []Condition{
+			{Name: "whitelist", Result: Pass, Reason: `pull request has a whitelist label: "merge|now"`},
+			{Name: `status "ci"`, Result: Pending, Reason: "status check has not succeeded: 2 of 3\njobs done"},
+		}},
+		Update: &Decision{},
+		MergeAttempt: &MergeAttempt{
+			Reason: "GitHub responded with status 405: Required status check \"ci\" is expected.",
+		},
+	}
+
+	expected := "Bulldozer tried to merge the pull request, but the merge failed: GitHub responded with status 405: Required status check \"ci\" is expected.\n" +
+		"\n" +
+		"### Merge: pending\n" +
+		"\n" +
+		"| Condition | Result | Reason |\n" +
+		"| --- | --- | --- |\n" +
+		"| whitelist | pass | pull request has a whitelist label: \"merge\\|now\" |\n" +
+		"| status \"ci\" | pending | status check has not succeeded: 2 of 3 jobs done |\n" +
+		"\n" +
+		"### Update: pass\n" +
+		"\n" +
+		"No conditions were evaluated."
+
+	assert.Equal(t, expected, report.Summary())
+}
+
+func TestReportingMerger(t *testing.T) {
+	ctx := context.Background()
+	pc := &pulltest.MockPullContext{}
+	decision := &Decision{Conditions: []Condition{{Name: "whitelist", Result: Pass}}}
+
+	t.Run("merged", func(t *testing.T) {
+		reporter := &MockReporter{}
+		merger := NewReportingMerger(&MockMerger{}, reporter, Report{Merge: decision})
+
+		sha, err := merger.Merge(ctx, pc, MergeCommit, CommitMessage{}, "abc")
+		require.NoError(t, err)
+		assert.Equal(t, "deadbeef", sha)
+
+		require.Len(t, reporter.Reports, 1)
+		assert.Equal(t, decision, reporter.Reports[0].Merge)
+		assert.Equal(t, &MergeAttempt{Merged: true, SHA: "deadbeef"}, reporter.Reports[0].MergeAttempt)
+	})
+
+	t.Run("rejected", func(t *testing.T) {
+		reporter := &MockReporter{}
+		merger := NewReportingMerger(&MockMerger{
+			MergeError: &github.ErrorResponse{
+				Response: &http.Response{StatusCode: http.StatusMethodNotAllowed},
+				Message:  "At least 1 approving review is required by reviewers with write access.",
+			},
+		}, reporter, Report{Merge: decision})
+
+		_, err := merger.Merge(ctx, pc, MergeCommit, CommitMessage{}, "abc")
+		require.Error(t, err)
+
+		require.Len(t, reporter.Reports, 1)
+		assert.False(t, reporter.Reports[0].MergeAttempt.Merged)
+		assert.Equal(t, "GitHub responded with status 405: At least 1 approving review is required by reviewers with write access.", reporter.Reports[0].MergeAttempt.Reason)
+	})
+}
@@ -0,0 +1,171 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+func (r *RequiredReviews) Enabled() bool {
+	return r.MinApprovals > 0 || r.BlockOnChangesRequested
+}
+
+// reviewStates holds the latest state of the reviews of each reviewer.
+type reviewStates struct {
+	approved         []string
+	staleApproved    []string
+	changesRequested []string
+}
+
+// latestReviewStates computes the current state of each reviewer. Comments do
+// not change the state of a reviewer, while a dismissal resets it.
+func latestReviewStates(reviews []*pull.Review, headSHA string, dismissStale bool) reviewStates {
+	var order []string
+	latest := make(map[string]*pull.Review)
+	for _, r := range reviews {
+		switch r.State {
+		case pull.ReviewApproved, pull.ReviewChangesRequested, pull.ReviewDismissed:
+			key := strings.ToLower(r.Author)
+			if _, seen := latest[key]; !seen {
+				order = append(order, key)
+			}
+			latest[key] = r
+		}
+	}
+
+	var states reviewStates
+	for _, key := range order {
+		r := latest[key]
+		switch r.State {
+		case pull.ReviewApproved:
+			if dismissStale && r.CommitSHA != headSHA {
+				states.staleApproved = append(states.staleApproved, r.Author)
+			} else {
+				states.approved = append(states.approved, r.Author)
+			}
+		case pull.ReviewChangesRequested:
+			states.changesRequested = append(states.changesRequested, r.Author)
+		}
+	}
+	return states
+}
+
+// HasRequiredReviews returns true if the reviews on the PR satisfy the
+// requirements, false otherwise. Only reviews from users with write access
+// count. Additionally, a description of the reason will be returned.
+func HasRequiredReviews(ctx context.Context, pullCtx pull.Context, requirements RequiredReviews) (bool, string, error) {
+	reviews, err := pullCtx.Reviews(ctx)
+	if err != nil {
+		return false, "unable to list pull request reviews", err
+	}
+
+	states := latestReviewStates(reviews, pullCtx.HeadSHA(), requirements.DismissStaleApprovalsOnPush)
+
+	if requirements.BlockOnChangesRequested {
+		changesRequested, err := withWriteAccess(ctx, pullCtx, states.changesRequested)
+		if err != nil {
+			return false, "unable to determine permissions of reviewers", err
+		}
+		if len(changesRequested) > 0 {
+			return false, fmt.Sprintf("changes were requested by %s", formatUsers(changesRequested)), nil
+		}
+	}
+
+	approved, err := withWriteAccess(ctx, pullCtx, states.approved)
+	if err != nil {
+		return false, "unable to determine permissions of reviewers", err
+	}
+	if len(approved) < requirements.MinApprovals {
+		reason := fmt.Sprintf("pull request has %d of %d required approvals", len(approved), requirements.MinApprovals)
+		if len(approved) > 0 {
+			reason += fmt.Sprintf(", approved by %s", formatUsers(approved))
+		}
+		if len(states.staleApproved) > 0 {
+			reason += fmt.Sprintf(", approvals by %s are stale", formatUsers(states.staleApproved))
+		}
+		if len(approved) < len(states.approved) {
+			reason += ", approvals from users without write access do not count"
+		}
+		return false, reason, nil
+	}
+
+	if requirements.MinApprovals == 0 {
+		return true, "no reviewer requested changes", nil
+	}
+	return true, fmt.Sprintf("pull request has %d of %d required approvals", len(approved), requirements.MinApprovals), nil
+}
+
+// HasProtectionReviews returns true if the reviews on the PR satisfy the
+// review requirements of branch protection, false otherwise. Like GitHub, it
+// only counts reviews from users with write access. Additionally, a
+// description of the reason will be returned.
+func HasProtectionReviews(ctx context.Context, pullCtx pull.Context, protection *pull.ReviewProtection) (bool, string, error) {
+	reviews, err := pullCtx.Reviews(ctx)
+	if err != nil {
+		return false, "unable to list pull request reviews", err
+	}
+
+	// GitHub dismisses stale approvals itself, so they are never stale here
+	states := latestReviewStates(reviews, pullCtx.HeadSHA(), false)
+
+	changesRequested, err := withWriteAccess(ctx, pullCtx, states.changesRequested)
+	if err != nil {
+		return false, "unable to determine permissions of reviewers", err
+	}
+	if len(changesRequested) > 0 {
+		return false, fmt.Sprintf("changes were requested by %s", formatUsers(changesRequested)), nil
+	}
+
+	approved, err := withWriteAccess(ctx, pullCtx, states.approved)
+	if err != nil {
+		return false, "unable to determine permissions of reviewers", err
+	}
+	reason := fmt.Sprintf("pull request has %d of %d approvals required by branch protection", len(approved), protection.RequiredApprovals)
+	if len(approved) < protection.RequiredApprovals {
+		if len(approved) < len(states.approved) {
+			reason += ", approvals from users without write access do not count"
+		}
+		return false, reason, nil
+	}
+	return true, reason, nil
+}
+
+// withWriteAccess returns the users with at least write access to the
+// repository.
+func withWriteAccess(ctx context.Context, pullCtx pull.Context, users []string) ([]string, error) {
+	var writers []string
+	for _, user := range users {
+		permission, err := pullCtx.Permission(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+		if permissionRanks[permission] >= permissionRanks["write"] {
+			writers = append(writers, user)
+		}
+	}
+	return writers, nil
+}
+
+func formatUsers(users []string) string {
+	formatted := make([]string, len(users))
+	for i, u := range users {
+		formatted[i] = "@" + u
+	}
+	return strings.Join(formatted, ", ")
+}
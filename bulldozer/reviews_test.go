@@ -0,0 +1,278 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull"
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+func TestHasRequiredReviews(t *testing.T) {
+	ctx := context.Background()
+
+	permissions := map[string]string{
+		"alice":   "write",
+		"Alice":   "write",
+		"bob":     "admin",
+		"mallory": "read",
+	}
+
+	tests := map[string]struct {
+		Requirements RequiredReviews
+		Reviews      []*pull.Review
+		Reviewed     bool
+		Reason       string
+	}{
+		"enoughApprovals": {
+			Requirements: RequiredReviews{MinApprovals: 2},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "old"},
+				{Author: "bob", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "pull request has 2 of 2 required approvals",
+		},
+		"missingApprovals": {
+			Requirements: RequiredReviews{MinApprovals: 2},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "bob", State: pull.ReviewCommented, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 1 of 2 required approvals, approved by @alice",
+		},
+		"repeatedApprovalsCountOnce": {
+			Requirements: RequiredReviews{MinApprovals: 2},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "old"},
+				{Author: "Alice", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 1 of 2 required approvals, approved by @Alice",
+		},
+		"commentDoesNotResetApproval": {
+			Requirements: RequiredReviews{MinApprovals: 1},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "alice", State: pull.ReviewCommented, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "pull request has 1 of 1 required approvals",
+		},
+		"dismissalResetsApproval": {
+			Requirements: RequiredReviews{MinApprovals: 1},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "alice", State: pull.ReviewDismissed, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 0 of 1 required approvals",
+		},
+		"staleApprovalDismissed": {
+			Requirements: RequiredReviews{MinApprovals: 2, DismissStaleApprovalsOnPush: true},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "old"},
+				{Author: "bob", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 1 of 2 required approvals, approved by @bob, approvals by @alice are stale",
+		},
+		"changesRequestedBlocks": {
+			Requirements: RequiredReviews{MinApprovals: 1, BlockOnChangesRequested: true},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "bob", State: pull.ReviewChangesRequested, CommitSHA: "old"},
+			},
+			Reviewed: false,
+			Reason:   "changes were requested by @bob",
+		},
+		"changesRequestedThenApproved": {
+			Requirements: RequiredReviews{BlockOnChangesRequested: true},
+			Reviews: []*pull.Review{
+				{Author: "bob", State: pull.ReviewChangesRequested, CommitSHA: "old"},
+				{Author: "bob", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "no reviewer requested changes",
+		},
+		"changesRequestedIgnored": {
+			Requirements: RequiredReviews{MinApprovals: 1},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "bob", State: pull.ReviewChangesRequested, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "pull request has 1 of 1 required approvals",
+		},
+		"approvalsWithoutWriteAccess": {
+			Requirements: RequiredReviews{MinApprovals: 2},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "mallory", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 1 of 2 required approvals, approved by @alice, approvals from users without write access do not count",
+		},
+		"changesRequestedWithoutWriteAccess": {
+			Requirements: RequiredReviews{MinApprovals: 1, BlockOnChangesRequested: true},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "mallory", State: pull.ReviewChangesRequested, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "pull request has 1 of 1 required approvals",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{
+				HeadSHAValue:    "head",
+				ReviewsValue:    test.Reviews,
+				PermissionValue: permissions,
+			}
+
+			reviewed, reason, err := HasRequiredReviews(ctx, pc, test.Requirements)
+			require.NoError(t, err)
+			assert.Equal(t, test.Reviewed, reviewed)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+
+	t.Run("failureObtainingReviews", func(t *testing.T) {
+		pc := &pulltest.MockPullContext{
+			ReviewsErrValue: errors.New("can't get reviews"),
+		}
+
+		reviewed, _, err := HasRequiredReviews(ctx, pc, RequiredReviews{MinApprovals: 1})
+		require.Error(t, err)
+		assert.False(t, reviewed)
+	})
+}
+
+func TestHasProtectionReviews(t *testing.T) {
+	ctx := context.Background()
+
+	permissions := map[string]string{
+		"alice":   "write",
+		"bob":     "admin",
+		"mallory": "read",
+	}
+
+	tests := map[string]struct {
+		Protection pull.ReviewProtection
+		Reviews    []*pull.Review
+		Reviewed   bool
+		Reason     string
+	}{
+		"enoughApprovals": {
+			Protection: pull.ReviewProtection{RequiredApprovals: 2},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "old"},
+				{Author: "bob", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "pull request has 2 of 2 approvals required by branch protection",
+		},
+		"approvalsWithoutWriteAccess": {
+			Protection: pull.ReviewProtection{RequiredApprovals: 2},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "mallory", State: pull.ReviewApproved, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 1 of 2 approvals required by branch protection, approvals from users without write access do not count",
+		},
+		"changesRequested": {
+			Protection: pull.ReviewProtection{RequiredApprovals: 1},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "bob", State: pull.ReviewChangesRequested, CommitSHA: "head"},
+			},
+			Reviewed: false,
+			Reason:   "changes were requested by @bob",
+		},
+		"changesRequestedWithoutWriteAccess": {
+			Protection: pull.ReviewProtection{RequiredApprovals: 1},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "head"},
+				{Author: "mallory", State: pull.ReviewChangesRequested, CommitSHA: "head"},
+			},
+			Reviewed: true,
+			Reason:   "pull request has 1 of 1 approvals required by branch protection",
+		},
+		"dismissedApproval": {
+			Protection: pull.ReviewProtection{RequiredApprovals: 1, DismissStaleReviews: true},
+			Reviews: []*pull.Review{
+				{Author: "alice", State: pull.ReviewApproved, CommitSHA: "old"},
+				{Author: "alice", State: pull.ReviewDismissed, CommitSHA: "old"},
+			},
+			Reviewed: false,
+			Reason:   "pull request has 0 of 1 approvals required by branch protection",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{
+				HeadSHAValue:    "head",
+				ReviewsValue:    test.Reviews,
+				PermissionValue: permissions,
+			}
+
+			reviewed, reason, err := HasProtectionReviews(ctx, pc, &test.Protection)
+			require.NoError(t, err)
+			assert.Equal(t, test.Reviewed, reviewed)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+}
+
+func TestRequiredReviewsEnabled(t *testing.T) {
+	tests := map[string]struct {
+		Reviews RequiredReviews
+		Enabled bool
+	}{
+		"disabled": {
+			Reviews: RequiredReviews{},
+			Enabled: false,
+		},
+		"minApprovals": {
+			Reviews: RequiredReviews{MinApprovals: 1},
+			Enabled: true,
+		},
+		"blockOnChangesRequested": {
+			Reviews: RequiredReviews{BlockOnChangesRequested: true},
+			Enabled: true,
+		},
+		"codeOwnerReviewOnly": {
+			Reviews: RequiredReviews{RequireCodeOwnerReview: true},
+			Enabled: false,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Enabled, test.Reviews.Enabled())
+		})
+	}
+}
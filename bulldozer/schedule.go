@@ -0,0 +1,295 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	clockLayout = "15:04"
+	dateLayout  = "2006-01-02"
+
+	// maxScheduleLookahead bounds the search for the next opening of a
+	// schedule, so that schedules that never open do not loop forever
+	maxScheduleLookahead = 400 * 24 * time.Hour
+)
+
+// Schedule restricts the times at which bulldozer acts on pull requests.
+type Schedule struct {
+	// Timezone is the IANA name of the timezone windows and freezes are
+	// expressed in. Defaults to UTC.
+	Timezone string `yaml:"timezone"`
+
+	// Windows are the periods of the week during which bulldozer may act.
+	// If empty, bulldozer may act at any time outside of freezes.
+	Windows []ScheduleWindow `yaml:"windows"`
+
+	// Freezes are date ranges during which bulldozer never acts.
+	Freezes []ScheduleFreeze `yaml:"freezes"`
+}
+
+type ScheduleWindow struct {
+	// Days are the weekdays the window applies to, either full names or
+	// three letter abbreviations. If empty, the window applies to every day.
+	Days []string `yaml:"days"`
+
+	// Start and End are the times of day bounding the window, in the 24 hour
+	// "HH:MM" format. End may be "24:00". Default to the start and the end of
+	// the day.
+	Start string `yaml:"start"`
+	End   string `yaml:"end"`
+}
+
+type ScheduleFreeze struct {
+	Name string `yaml:"name"`
+
+	// From and To are the first and the last day of the freeze, in the
+	// "YYYY-MM-DD" format.
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+type window struct {
+	days       map[time.Weekday]bool
+	start, end time.Duration
+}
+
+type freeze struct {
+	name     string
+	from, to time.Time
+}
+
+type compiledSchedule struct {
+	location *time.Location
+	windows  []window
+	freezes  []freeze
+}
+
+// now returns the current time, and is overridden in tests
+var now = time.Now
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
+
+func (s *Schedule) Enabled() bool {
+	return len(s.Windows) > 0 || len(s.Freezes) > 0
+}
+
+func (s *Schedule) validate() error {
+	_, err := s.compile()
+	return err
+}
+
+// IsOpen returns true if bulldozer may act at time t, false otherwise.
+// Additionally, a description of the reason will be returned.
+func (s *Schedule) IsOpen(t time.Time) (bool, string, error) {
+	cs, err := s.compile()
+	if err != nil {
+		return false, "invalid schedule", err
+	}
+	open, reason := cs.isOpen(t)
+	return open, reason, nil
+}
+
+// IsPRScheduled returns true if the schedule allows acting on pull requests
+// at the current time, false otherwise. Additionally, a description of the
+// reason will be returned.
+func IsPRScheduled(ctx context.Context, schedule Schedule) (bool, string, error) {
+	open, reason, err := schedule.IsOpen(now())
+	if err != nil {
+		// schedules must always fail closed (closed on error)
+		return false, reason, err
+	}
+	return open, reason, nil
+}
+
+// NextOpening returns the earliest time after t at which the schedule is
+// open. If the schedule is already open at t, t is returned. The boolean
+// result is false if the schedule does not open in the foreseeable future.
+func (s *Schedule) NextOpening(t time.Time) (time.Time, bool, error) {
+	cs, err := s.compile()
+	if err != nil {
+		return time.Time{}, false, err
+	}
+
+	if open, _ := cs.isOpen(t); open {
+		return t, true, nil
+	}
+
+	// the schedule can only open at the start of a window or at the end of a
+	// freeze, so it is enough to check these times in order
+	var candidates []time.Time
+	local := t.In(cs.location)
+	for day := midnight(local); day.Sub(local) < maxScheduleLookahead; day = day.AddDate(0, 0, 1) {
+		for _, w := range cs.windows {
+			if w.days[day.Weekday()] {
+				candidates = append(candidates, addClock(day, w.start))
+			}
+		}
+	}
+	for _, f := range cs.freezes {
+		candidates = append(candidates, f.to)
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].Before(candidates[j]) })
+	for _, c := range candidates {
+		if !c.After(t) {
+			continue
+		}
+		if open, _ := cs.isOpen(c); open {
+			return c, true, nil
+		}
+	}
+	return time.Time{}, false, nil
+}
+
+func (cs *compiledSchedule) isOpen(t time.Time) (bool, string) {
+	local := t.In(cs.location)
+
+	for _, f := range cs.freezes {
+		if !local.Before(f.from) && local.Before(f.to) {
+			return false, fmt.Sprintf("the %s freeze is in effect", f.name)
+		}
+	}
+
+	if len(cs.windows) == 0 {
+		return true, "no freeze is in effect"
+	}
+
+	clock := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute
+	for _, w := range cs.windows {
+		if w.days[local.Weekday()] && clock >= w.start && clock < w.end {
+			return true, "the current time is within a scheduled window"
+		}
+	}
+	return false, fmt.Sprintf("%s is outside of all scheduled windows", local.Format("Monday 15:04 MST"))
+}
+
+func (s *Schedule) compile() (*compiledSchedule, error) {
+	location := time.UTC
+	if s.Timezone != "" {
+		loc, err := time.LoadLocation(s.Timezone)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid timezone %q", s.Timezone)
+		}
+		location = loc
+	}
+
+	cs := &compiledSchedule{location: location}
+
+	for i, w := range s.Windows {
+		days := make(map[time.Weekday]bool)
+		for _, d := range w.Days {
+			day, ok := parseWeekday(d)
+			if !ok {
+				return nil, errors.Errorf("invalid day %q in window %d", d, i)
+			}
+			days[day] = true
+		}
+		if len(days) == 0 {
+			for _, day := range weekdays {
+				days[day] = true
+			}
+		}
+
+		start, err := parseClock(w.Start, 0)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid start of window %d", i)
+		}
+		end, err := parseClock(w.End, 24*time.Hour)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid end of window %d", i)
+		}
+		if end <= start {
+			return nil, errors.Errorf("window %d ends before it starts", i)
+		}
+
+		cs.windows = append(cs.windows, window{days: days, start: start, end: end})
+	}
+
+	for i, f := range s.Freezes {
+		from, err := time.ParseInLocation(dateLayout, f.From, location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid start of freeze %d", i)
+		}
+		to, err := time.ParseInLocation(dateLayout, f.To, location)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid end of freeze %d", i)
+		}
+		if to.Before(from) {
+			return nil, errors.Errorf("freeze %d ends before it starts", i)
+		}
+
+		name := f.Name
+		if name == "" {
+			name = fmt.Sprintf("%s to %s", f.From, f.To)
+		}
+
+		// the last day of a freeze is inclusive
+		cs.freezes = append(cs.freezes, freeze{name: name, from: from, to: to.AddDate(0, 0, 1)})
+	}
+
+	return cs, nil
+}
+
+func parseWeekday(s string) (time.Weekday, bool) {
+	s = strings.ToLower(s)
+	for name, day := range weekdays {
+		if s == name || s == name[:3] {
+			return day, true
+		}
+	}
+	return 0, false
+}
+
+// parseClock parses a time of day into the duration since midnight. The
+// empty string parses as the given default.
+func parseClock(s string, def time.Duration) (time.Duration, error) {
+	if s == "" {
+		return def, nil
+	}
+	if s == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, errors.Errorf("expected time of day in the HH:MM format, got %q", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func midnight(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// addClock returns the time of day d on the day of midnight m, accounting
+// for days that are shorter or longer because of daylight saving time.
+func addClock(m time.Time, d time.Duration) time.Time {
+	return time.Date(m.Year(), m.Month(), m.Day(), 0, int(d/time.Minute), 0, 0, m.Location())
+}
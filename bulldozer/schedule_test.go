@@ -0,0 +1,239 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScheduleIsOpen(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	schedule := Schedule{
+		Timezone: "Europe/Berlin",
+		Windows: []ScheduleWindow{
+			{Days: []string{"Mon", "tuesday", "WED", "thu"}, Start: "08:00", End: "18:00"},
+			{Days: []string{"fri"}, Start: "08:00", End: "15:00"},
+		},
+		Freezes: []ScheduleFreeze{
+			{Name: "holiday", From: "2026-12-21", To: "2027-01-01"},
+		},
+	}
+
+	tests := map[string]struct {
+		Time   time.Time
+		Open   bool
+		Reason string
+	}{
+		"withinWindow": {
+			Time:   time.Date(2026, 10, 14, 10, 0, 0, 0, berlin),
+			Open:   true,
+			Reason: "the current time is within a scheduled window",
+		},
+		"fridayAfternoon": {
+			Time:   time.Date(2026, 10, 16, 15, 0, 0, 0, berlin),
+			Open:   false,
+			Reason: "Friday 15:00 CEST is outside of all scheduled windows",
+		},
+		"weekend": {
+			Time:   time.Date(2026, 10, 17, 10, 0, 0, 0, berlin),
+			Open:   false,
+			Reason: "Saturday 10:00 CEST is outside of all scheduled windows",
+		},
+		"windowInOtherTimezone": {
+			Time:   time.Date(2026, 10, 14, 7, 30, 0, 0, time.UTC),
+			Open:   true,
+			Reason: "the current time is within a scheduled window",
+		},
+		"firstDayOfFreeze": {
+			Time:   time.Date(2026, 12, 21, 10, 0, 0, 0, berlin),
+			Open:   false,
+			Reason: "the holiday freeze is in effect",
+		},
+		"lastDayOfFreeze": {
+			Time:   time.Date(2027, 1, 1, 10, 0, 0, 0, berlin),
+			Open:   false,
+			Reason: "the holiday freeze is in effect",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			open, reason, err := schedule.IsOpen(test.Time)
+			require.NoError(t, err)
+			assert.Equal(t, test.Open, open)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+}
+
+func TestIsPRScheduled(t *testing.T) {
+	defer func(previous func() time.Time) { now = previous }(now)
+
+	schedule := Schedule{
+		Windows: []ScheduleWindow{{Days: []string{"fri"}, End: "15:00"}},
+	}
+
+	tests := map[string]struct {
+		Now       time.Time
+		Schedule  Schedule
+		Scheduled bool
+		Error     bool
+	}{
+		"withinSchedule": {
+			Now:       time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC),
+			Schedule:  schedule,
+			Scheduled: true,
+		},
+		"outsideSchedule": {
+			Now:       time.Date(2026, 10, 16, 16, 0, 0, 0, time.UTC),
+			Schedule:  schedule,
+			Scheduled: false,
+		},
+		"invalidSchedule": {
+			Now:       time.Date(2026, 10, 16, 14, 0, 0, 0, time.UTC),
+			Schedule:  Schedule{Timezone: "Mars/Olympus_Mons", Windows: schedule.Windows},
+			Scheduled: false,
+			Error:     true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			now = func() time.Time { return test.Now }
+
+			scheduled, _, err := IsPRScheduled(context.Background(), test.Schedule)
+			if test.Error {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, test.Scheduled, scheduled)
+		})
+	}
+}
+
+func TestScheduleNextOpening(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	require.NoError(t, err)
+
+	schedule := Schedule{
+		Timezone: "Europe/Berlin",
+		Windows: []ScheduleWindow{
+			{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "08:00", End: "18:00"},
+		},
+		Freezes: []ScheduleFreeze{
+			{From: "2026-12-21", To: "2027-01-01"},
+		},
+	}
+
+	tests := map[string]struct {
+		Time time.Time
+		Next time.Time
+	}{
+		"alreadyOpen": {
+			Time: time.Date(2026, 10, 14, 10, 0, 0, 0, berlin),
+			Next: time.Date(2026, 10, 14, 10, 0, 0, 0, berlin),
+		},
+		"beforeWindow": {
+			Time: time.Date(2026, 10, 14, 6, 0, 0, 0, berlin),
+			Next: time.Date(2026, 10, 14, 8, 0, 0, 0, berlin),
+		},
+		"overWeekend": {
+			Time: time.Date(2026, 10, 16, 19, 0, 0, 0, berlin),
+			Next: time.Date(2026, 10, 19, 8, 0, 0, 0, berlin),
+		},
+		"acrossDaylightSavingTime": {
+			Time: time.Date(2026, 10, 23, 19, 0, 0, 0, berlin),
+			Next: time.Date(2026, 10, 26, 8, 0, 0, 0, berlin),
+		},
+		"afterFreeze": {
+			Time: time.Date(2026, 12, 22, 10, 0, 0, 0, berlin),
+			Next: time.Date(2027, 1, 4, 8, 0, 0, 0, berlin),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			next, ok, err := schedule.NextOpening(test.Time)
+			require.NoError(t, err)
+			assert.True(t, ok)
+			assert.True(t, test.Next.Equal(next), "expected %s, got %s", test.Next, next)
+		})
+	}
+
+	t.Run("neverOpens", func(t *testing.T) {
+		schedule := Schedule{
+			Windows: []ScheduleWindow{{Days: []string{"mon"}}},
+			Freezes: []ScheduleFreeze{{From: "2026-01-01", To: "2030-12-31"}},
+		}
+
+		_, ok, err := schedule.NextOpening(time.Date(2026, 10, 14, 10, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestScheduleValidate(t *testing.T) {
+	tests := map[string]struct {
+		Schedule Schedule
+		Error    string
+	}{
+		"valid": {
+			Schedule: Schedule{
+				Timezone: "America/New_York",
+				Windows:  []ScheduleWindow{{Days: []string{"sat", "Sunday"}, Start: "10:00", End: "24:00"}},
+				Freezes:  []ScheduleFreeze{{From: "2026-12-24", To: "2026-12-24"}},
+			},
+		},
+		"invalidTimezone": {
+			Schedule: Schedule{Timezone: "Mars/Olympus_Mons"},
+			Error:    `invalid timezone "Mars/Olympus_Mons"`,
+		},
+		"invalidDay": {
+			Schedule: Schedule{Windows: []ScheduleWindow{{Days: []string{"someday"}}}},
+			Error:    `invalid day "someday" in window 0`,
+		},
+		"invalidStart": {
+			Schedule: Schedule{Windows: []ScheduleWindow{{Start: "9am"}}},
+			Error:    `invalid start of window 0: expected time of day in the HH:MM format, got "9am"`,
+		},
+		"emptyWindow": {
+			Schedule: Schedule{Windows: []ScheduleWindow{{Start: "15:00", End: "15:00"}}},
+			Error:    `window 0 ends before it starts`,
+		},
+		"reversedFreeze": {
+			Schedule: Schedule{Freezes: []ScheduleFreeze{{From: "2026-12-24", To: "2026-12-23"}}},
+			Error:    `freeze 0 ends before it starts`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.Schedule.validate()
+			if test.Error == "" {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), test.Error)
+			}
+		})
+	}
+}
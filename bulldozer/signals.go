@@ -17,7 +17,11 @@ package bulldozer
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
+	"time"
+
+	"github.com/pkg/errors"
 
 	"github.com/ridge/bulldozer/pull"
 )
@@ -28,6 +32,68 @@ type Signals struct {
 	Comments          []string `yaml:"comments"`
 	PRBodySubstrings  []string `yaml:"pr_body_substrings"`
 	Branches          []string `yaml:"branches"`
+
+	LabelPatterns   []string `yaml:"label_patterns"`
+	CommentPatterns []string `yaml:"comment_patterns"`
+	PRBodyPatterns  []string `yaml:"pr_body_patterns"`
+	BranchPatterns  []string `yaml:"branch_patterns"`
+
+	Authors            []string `yaml:"authors"`
+	AuthorTeams        []string `yaml:"author_teams"`
+	AuthorAssociations []string `yaml:"author_associations"`
+
+	// HeadBranches are globs matched against the head branch. Patterns
+	// containing a colon match heads in forks, qualified by the fork owner
+	// (e.g. "user:branch"). Other patterns only match heads in the
+	// repository itself.
+	HeadBranches []string `yaml:"head_branches"`
+
+	ChangedPaths     []string `yaml:"changed_paths"`
+	OnlyChangedPaths []string `yaml:"only_changed_paths"`
+
+	// CommentMinPermission limits comment signals to comments (and pull
+	// request bodies) written by users with at least this permission on the
+	// repository.
+	CommentMinPermission string `yaml:"comment_min_permission"`
+
+	// InvalidateCommentsOnPush limits comment signals to comments created
+	// after the head of the pull request was pushed. Pull request bodies never
+	// trigger comment signals, because the time they were written is unknown.
+	InvalidateCommentsOnPush bool `yaml:"invalidate_comments_on_push"`
+
+	// compiled holds the expressions compiled when the configuration was
+	// validated. Copies of the signals share them.
+	compiled *compiledSignals
+}
+
+// compiledSignals are the compiled regular expressions and globs of Signals.
+type compiledSignals struct {
+	labelPatterns    []*regexp.Regexp
+	commentPatterns  []*regexp.Regexp
+	prBodyPatterns   []*regexp.Regexp
+	branchPatterns   []*regexp.Regexp
+	headBranches     []*regexp.Regexp
+	changedPaths     []*regexp.Regexp
+	onlyChangedPaths []*regexp.Regexp
+}
+
+// permissionRanks orders the repository permission levels reported by GitHub
+var permissionRanks = map[string]int{
+	"none":  0,
+	"read":  1,
+	"write": 2,
+	"admin": 3,
+}
+
+var authorAssociations = []string{
+	"OWNER",
+	"MEMBER",
+	"COLLABORATOR",
+	"CONTRIBUTOR",
+	"FIRST_TIME_CONTRIBUTOR",
+	"FIRST_TIMER",
+	"MANNEQUIN",
+	"NONE",
 }
 
 func (s *Signals) Enabled() bool {
@@ -37,14 +103,230 @@ func (s *Signals) Enabled() bool {
 	size += len(s.Comments)
 	size += len(s.PRBodySubstrings)
 	size += len(s.Branches)
+	size += len(s.LabelPatterns)
+	size += len(s.CommentPatterns)
+	size += len(s.PRBodyPatterns)
+	size += len(s.BranchPatterns)
+	size += len(s.Authors)
+	size += len(s.AuthorTeams)
+	size += len(s.AuthorAssociations)
+	size += len(s.HeadBranches)
+	size += len(s.ChangedPaths)
+	size += len(s.OnlyChangedPaths)
 	return size > 0
 }
 
+// validate checks that all regular expressions in the signals compile and
+// keeps the compiled expressions for later evaluations.
+func (s *Signals) validate() error {
+	compiled, err := s.compile()
+	if err != nil {
+		return err
+	}
+	s.compiled = compiled
+
+	for _, team := range s.AuthorTeams {
+		if parts := strings.SplitN(teamName(team), "/", 2); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return errors.Errorf("invalid author_teams entry %q, expected <organization>/<team slug>", team)
+		}
+	}
+
+	for _, association := range s.AuthorAssociations {
+		if !containsFold(authorAssociations, association) {
+			return errors.Errorf("invalid author_associations entry %q, expected one of %s", association, strings.Join(authorAssociations, ", "))
+		}
+	}
+
+	if s.CommentMinPermission != "" {
+		if rank, ok := permissionRanks[s.CommentMinPermission]; !ok || rank == 0 {
+			return errors.Errorf("invalid comment_min_permission %q, expected one of read, write, admin", s.CommentMinPermission)
+		}
+	}
+	return nil
+}
+
+// teamName strips the optional "@" prefix from a team reference.
+func teamName(team string) string {
+	return strings.TrimPrefix(team, "@")
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// compile compiles the regular expressions and globs of the signals.
+func (s *Signals) compile() (*compiledSignals, error) {
+	var c compiledSignals
+	var err error
+
+	for _, set := range []struct {
+		key      string
+		patterns []string
+		rxs      *[]*regexp.Regexp
+	}{
+		{"label_patterns", s.LabelPatterns, &c.labelPatterns},
+		{"comment_patterns", s.CommentPatterns, &c.commentPatterns},
+		{"pr_body_patterns", s.PRBodyPatterns, &c.prBodyPatterns},
+		{"branch_patterns", s.BranchPatterns, &c.branchPatterns},
+	} {
+		if *set.rxs, err = compilePatterns(set.patterns); err != nil {
+			return nil, errors.Wrapf(err, "invalid syntax of %s", set.key)
+		}
+	}
+	for _, set := range []struct {
+		key   string
+		globs []string
+		rxs   *[]*regexp.Regexp
+	}{
+		{"head_branches", s.HeadBranches, &c.headBranches},
+		{"changed_paths", s.ChangedPaths, &c.changedPaths},
+		{"only_changed_paths", s.OnlyChangedPaths, &c.onlyChangedPaths},
+	} {
+		if *set.rxs, err = compileGlobs(set.globs); err != nil {
+			return nil, errors.Wrapf(err, "invalid syntax of %s", set.key)
+		}
+	}
+	return &c, nil
+}
+
+// expressions returns the compiled expressions of the signals, compiling
+// them if the signals were not validated.
+func (s *Signals) expressions() (*compiledSignals, error) {
+	if s.compiled != nil {
+		return s.compiled, nil
+	}
+	return s.compile()
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	rxs := make([]*regexp.Regexp, len(patterns))
+	for i, pattern := range patterns {
+		rx, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, errors.Wrapf(err, "pattern %q", pattern)
+		}
+		rxs[i] = rx
+	}
+	return rxs, nil
+}
+
+func (s *Signals) hasCommentSignals() bool {
+	return len(s.Comments)+len(s.CommentSubstrings)+len(s.CommentPatterns) > 0
+}
+
+// trustedComments returns the bodies of the comments that may trigger comment
+// signals and whether the pull request body may trigger them. If comments are
+// invalidated on push, only comments newer than the latest commit are
+// trusted. If a minimum permission is configured, only text written by users
+// with that permission is trusted.
+func (s *Signals) trustedComments(ctx context.Context, pullCtx pull.Context, comments []*pull.Comment) (bool, []string, error) {
+	if (s.InvalidateCommentsOnPush || s.CommentMinPermission != "") && !s.hasCommentSignals() {
+		return false, nil, nil
+	}
+
+	bodyTrusted := true
+	if s.InvalidateCommentsOnPush {
+		pushedAt, known, err := lastPushTime(ctx, pullCtx)
+		if err != nil {
+			return false, nil, err
+		}
+
+		bodyTrusted = false
+		var fresh []*pull.Comment
+		for _, c := range comments {
+			if known && c.CreatedAt.After(pushedAt) {
+				fresh = append(fresh, c)
+			}
+		}
+		comments = fresh
+	}
+
+	if s.CommentMinPermission == "" {
+		bodies := make([]string, len(comments))
+		for i, c := range comments {
+			bodies[i] = c.Body
+		}
+		return bodyTrusted, bodies, nil
+	}
+
+	if bodyTrusted {
+		trusted, err := s.hasCommentPermission(ctx, pullCtx, pullCtx.Author())
+		if err != nil {
+			return false, nil, err
+		}
+		bodyTrusted = trusted
+	}
+
+	var bodies []string
+	for _, c := range comments {
+		trusted, err := s.hasCommentPermission(ctx, pullCtx, c.Author)
+		if err != nil {
+			return false, nil, err
+		}
+		if trusted {
+			bodies = append(bodies, c.Body)
+		}
+	}
+	return bodyTrusted, bodies, nil
+}
+
+// lastPushTime returns the time GitHub received the head of the pull request.
+// If GitHub has no record of it, it falls back to the committer date of the
+// head commit, which the pusher controls. It returns false if neither is
+// known, in which case no comment counts.
+func lastPushTime(ctx context.Context, pullCtx pull.Context) (time.Time, bool, error) {
+	pushedAt, err := pullCtx.PushedAt(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if !pushedAt.IsZero() {
+		return pushedAt, true, nil
+	}
+
+	commits, err := pullCtx.Commits(ctx)
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	if len(commits) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	// commits are ordered from oldest to newest
+	head := commits[len(commits)-1]
+	for _, c := range commits {
+		if c.SHA == pullCtx.HeadSHA() {
+			head = c
+		}
+	}
+	return head.CommittedAt, !head.CommittedAt.IsZero(), nil
+}
+
+func (s *Signals) hasCommentPermission(ctx context.Context, pullCtx pull.Context, user string) (bool, error) {
+	if user == "" {
+		return false, nil
+	}
+	permission, err := pullCtx.Permission(ctx, user)
+	if err != nil {
+		return false, err
+	}
+	return permissionRanks[permission] >= permissionRanks[s.CommentMinPermission], nil
+}
+
 // Matches returns true if the pull request meets one or more signals. It also
 // returns a description of the signal that was met. The tag argument appears
 // in this description and indicates the behavior (whitelist, blacklist) this
 // set of signals is associated with.
 func (s *Signals) Matches(ctx context.Context, pullCtx pull.Context, tag string) (bool, string, error) {
+	compiled, err := s.expressions()
+	if err != nil {
+		return false, "invalid signal pattern", err
+	}
+
 	labels, err := pullCtx.Labels(ctx)
 	if err != nil {
 		return false, "unable to list pull request labels", err
@@ -58,17 +340,38 @@ func (s *Signals) Matches(ctx context.Context, pullCtx pull.Context, tag string)
 		}
 	}
 
+	for _, rx := range compiled.labelPatterns {
+		for _, label := range labels {
+			if rx.MatchString(label) {
+				return true, fmt.Sprintf("pull request label %q matches a %s pattern: %q", label, tag, rx), nil
+			}
+		}
+	}
+
 	body := pullCtx.Body()
 	comments, err := pullCtx.Comments(ctx)
 	if err != nil {
 		return false, "unable to list pull request comments", err
 	}
 
+	// bulldozer's own status comments quote signals and must never match them
+	var userComments []*pull.Comment
+	for _, c := range comments {
+		if !IsStatusComment(c.Body) {
+			userComments = append(userComments, c)
+		}
+	}
+
+	bodyTrusted, trustedComments, err := s.trustedComments(ctx, pullCtx, userComments)
+	if err != nil {
+		return false, "unable to determine trusted comments", err
+	}
+
 	for _, signalComment := range s.Comments {
-		if body == signalComment {
+		if bodyTrusted && body == signalComment {
 			return true, fmt.Sprintf("pull request body is a %s comment: %q", tag, signalComment), nil
 		}
-		for _, comment := range comments {
+		for _, comment := range trustedComments {
 			if comment == signalComment {
 				return true, fmt.Sprintf("pull request has a %s comment: %q", tag, signalComment), nil
 			}
@@ -76,23 +379,40 @@ func (s *Signals) Matches(ctx context.Context, pullCtx pull.Context, tag string)
 	}
 
 	for _, signalSubstring := range s.CommentSubstrings {
-		if strings.Contains(body, signalSubstring) {
+		if bodyTrusted && strings.Contains(body, signalSubstring) {
 			return true, fmt.Sprintf("pull request body matches a %s substring: %q", tag, signalSubstring), nil
 		}
-		for _, comment := range comments {
+		for _, comment := range trustedComments {
 			if strings.Contains(comment, signalSubstring) {
 				return true, fmt.Sprintf("pull request comment matches a %s substring: %q", tag, signalSubstring), nil
 			}
 		}
 	}
 
+	for _, rx := range compiled.commentPatterns {
+		if bodyTrusted && rx.MatchString(body) {
+			return true, fmt.Sprintf("pull request body matches a %s pattern: %q", tag, rx), nil
+		}
+		for _, comment := range trustedComments {
+			if rx.MatchString(comment) {
+				return true, fmt.Sprintf("pull request comment matches a %s pattern: %q", tag, rx), nil
+			}
+		}
+	}
+
 	for _, signalSubstring := range s.PRBodySubstrings {
 		if strings.Contains(body, signalSubstring) {
 			return true, fmt.Sprintf("pull request body matches a %s substring: %q", tag, signalSubstring), nil
 		}
 	}
 
-	targetBranch, _ := pullCtx.Branches()
+	for _, rx := range compiled.prBodyPatterns {
+		if rx.MatchString(body) {
+			return true, fmt.Sprintf("pull request body matches a %s pattern: %q", tag, rx), nil
+		}
+	}
+
+	targetBranch, headBranch := pullCtx.Branches()
 
 	for _, signalBranch := range s.Branches {
 		if targetBranch == signalBranch {
@@ -100,5 +420,89 @@ func (s *Signals) Matches(ctx context.Context, pullCtx pull.Context, tag string)
 		}
 	}
 
+	for _, rx := range compiled.branchPatterns {
+		if rx.MatchString(targetBranch) {
+			return true, fmt.Sprintf("pull request target %q matches a %s branch pattern: %q", targetBranch, tag, rx), nil
+		}
+	}
+
+	headInFork := strings.ContainsRune(headBranch, ':')
+	for i, rx := range compiled.headBranches {
+		// only fork-qualified patterns can match heads in forks
+		if strings.ContainsRune(s.HeadBranches[i], ':') != headInFork {
+			continue
+		}
+		if rx.MatchString(headBranch) {
+			return true, fmt.Sprintf("pull request head %q matches a %s head branch: %q", headBranch, tag, s.HeadBranches[i]), nil
+		}
+	}
+
+	author := pullCtx.Author()
+
+	for _, signalAuthor := range s.Authors {
+		if strings.EqualFold(author, signalAuthor) {
+			return true, fmt.Sprintf("pull request author is a %s author: %q", tag, signalAuthor), nil
+		}
+	}
+
+	association := pullCtx.AuthorAssociation()
+	for _, signalAssociation := range s.AuthorAssociations {
+		if strings.EqualFold(association, signalAssociation) {
+			return true, fmt.Sprintf("pull request author has a %s association: %q", tag, signalAssociation), nil
+		}
+	}
+
+	for _, signalTeam := range s.AuthorTeams {
+		member, err := pullCtx.IsTeamMember(ctx, teamName(signalTeam), author)
+		if err != nil {
+			return false, "unable to determine team membership of pull request author", err
+		}
+		if member {
+			return true, fmt.Sprintf("pull request author is a member of a %s team: %q", tag, signalTeam), nil
+		}
+	}
+
+	if len(s.ChangedPaths) > 0 || len(s.OnlyChangedPaths) > 0 {
+		matches, reason, err := s.matchesFiles(ctx, pullCtx, compiled, tag)
+		if err != nil || matches {
+			return matches, reason, err
+		}
+	}
+
 	return false, fmt.Sprintf("pull request does not match the %s", tag), nil
 }
+
+func (s *Signals) matchesFiles(ctx context.Context, pullCtx pull.Context, compiled *compiledSignals, tag string) (bool, string, error) {
+	files, err := pullCtx.Files(ctx)
+	if err != nil {
+		return false, "unable to list pull request files", err
+	}
+
+	for i, rx := range compiled.changedPaths {
+		for _, file := range files {
+			if rx.MatchString(file) {
+				return true, fmt.Sprintf("pull request changes %q matching a %s path: %q", file, tag, s.ChangedPaths[i]), nil
+			}
+		}
+	}
+
+	if len(compiled.onlyChangedPaths) > 0 && len(files) > 0 {
+		for _, file := range files {
+			if !matchesAny(compiled.onlyChangedPaths, file) {
+				return false, "", nil
+			}
+		}
+		return true, fmt.Sprintf("pull request only changes files matching the %s paths: [%s]", tag, strings.Join(s.OnlyChangedPaths, ",")), nil
+	}
+
+	return false, "", nil
+}
+
+func matchesAny(rxs []*regexp.Regexp, s string) bool {
+	for _, rx := range rxs {
+		if rx.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
@@ -17,6 +17,7 @@ package bulldozer
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -28,11 +29,18 @@ import (
 
 func TestSignalsMatches(t *testing.T) {
 	signals := Signals{
-		Labels:            []string{"LABEL_MERGE"},
-		Comments:          []string{"FULL_COMMENT_PLZ_MERGE"},
-		CommentSubstrings: []string{":+1:"},
-		PRBodySubstrings:  []string{"BODY_MERGE_PLZ"},
-		Branches:          []string{"develop"},
+		Labels:             []string{"LABEL_MERGE"},
+		Comments:           []string{"FULL_COMMENT_PLZ_MERGE"},
+		CommentSubstrings:  []string{":+1:"},
+		PRBodySubstrings:   []string{"BODY_MERGE_PLZ"},
+		Branches:           []string{"develop"},
+		LabelPatterns:      []string{"^automerge:"},
+		CommentPatterns:    []string{`(?i)^bulldozer,? merge`},
+		PRBodyPatterns:     []string{`\[x\] merge when ready`},
+		BranchPatterns:     []string{`^release/\d+\.\d+$`},
+		Authors:            []string{"dependabot[bot]"},
+		AuthorTeams:        []string{"@org/platform"},
+		AuthorAssociations: []string{"OWNER"},
 	}
 
 	ctx := context.Background()
@@ -44,14 +52,14 @@ func TestSignalsMatches(t *testing.T) {
 	}{
 		"noMatch": {
 			PullContext: &pulltest.MockPullContext{
-				CommentValue: []string{""},
+				CommentValue: []*pull.Comment{{Body: ""}},
 			},
 			Matches: false,
 			Reason:  `pull request does not match the testlist`,
 		},
 		"commentMatchesComment": {
 			PullContext: &pulltest.MockPullContext{
-				CommentValue: []string{"FULL_COMMENT_PLZ_MERGE"},
+				CommentValue: []*pull.Comment{{Body: "FULL_COMMENT_PLZ_MERGE"}},
 			},
 			Matches: true,
 			Reason:  `pull request has a testlist comment: "FULL_COMMENT_PLZ_MERGE"`,
@@ -59,7 +67,7 @@ func TestSignalsMatches(t *testing.T) {
 		"commentMatchesCommentSubstring": {
 			PullContext: &pulltest.MockPullContext{
 				LabelValue:   []string{"LABEL_nothing"},
-				CommentValue: []string{"a comment", "another comment", "this is good :+1: yep"},
+				CommentValue: []*pull.Comment{{Body: "a comment"}, {Body: "another comment"}, {Body: "this is good :+1: yep"}},
 			},
 			Matches: true,
 			Reason:  `pull request comment matches a testlist substring: ":+1:"`,
@@ -106,6 +114,80 @@ func TestSignalsMatches(t *testing.T) {
 			Matches: true,
 			Reason:  `pull request target is a testlist branch: "develop"`,
 		},
+		"labelMatchesLabelPattern": {
+			PullContext: &pulltest.MockPullContext{
+				LabelValue: []string{"automerge:squash"},
+			},
+			Matches: true,
+			Reason:  `pull request label "automerge:squash" matches a testlist pattern: "^automerge:"`,
+		},
+		"commentMatchesCommentPattern": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue: []*pull.Comment{{Body: "a comment"}, {Body: "Bulldozer, merge please"}},
+			},
+			Matches: true,
+			Reason:  `pull request comment matches a testlist pattern: "(?i)^bulldozer,? merge"`,
+		},
+		"bodyMatchesCommentPattern": {
+			PullContext: &pulltest.MockPullContext{
+				BodyValue: "bulldozer merge",
+			},
+			Matches: true,
+			Reason:  `pull request body matches a testlist pattern: "(?i)^bulldozer,? merge"`,
+		},
+		"bodyMatchesBodyPattern": {
+			PullContext: &pulltest.MockPullContext{
+				BodyValue: "Checklist:\n- [x] merge when ready",
+			},
+			Matches: true,
+			Reason:  `pull request body matches a testlist pattern: "\\[x\\] merge when ready"`,
+		},
+		"targetBranchMatchesBranchPattern": {
+			PullContext: &pulltest.MockPullContext{
+				BranchBase: "release/1.2",
+			},
+			Matches: true,
+			Reason:  `pull request target "release/1.2" matches a testlist branch pattern: "^release/\\d+\\.\\d+$"`,
+		},
+		"authorMatchesAuthor": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue: "Dependabot[bot]",
+			},
+			Matches: true,
+			Reason:  `pull request author is a testlist author: "dependabot[bot]"`,
+		},
+		"authorMatchesAssociation": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue:            "octocat",
+				AuthorAssociationValue: "OWNER",
+			},
+			Matches: true,
+			Reason:  `pull request author has a testlist association: "OWNER"`,
+		},
+		"authorMatchesTeam": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue:      "octocat",
+				TeamMembersValue: map[string][]string{"org/platform": {"octocat"}},
+			},
+			Matches: true,
+			Reason:  `pull request author is a member of a testlist team: "@org/platform"`,
+		},
+		"authorNotInTeam": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue:            "octocat",
+				AuthorAssociationValue: "MEMBER",
+				TeamMembersValue:       map[string][]string{"org/platform": {"hubot"}},
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"targetBranchDoesNotMatchBranchPattern": {
+			PullContext: &pulltest.MockPullContext{
+				BranchBase: "release/1.2-rc",
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
 	}
 
 	for name, test := range tests {
@@ -130,6 +212,8 @@ func TestSignalsFailures(t *testing.T) {
 		CommentSubstrings: []string{":+1:"},
 		PRBodySubstrings:  []string{"BODY_MERGE_PLZ"},
 		Branches:          []string{"develop"},
+		AuthorTeams:       []string{"org/platform"},
+		ChangedPaths:      []string{"migrations/**"},
 	}
 
 	ctx := context.Background()
@@ -151,6 +235,19 @@ func TestSignalsFailures(t *testing.T) {
 			},
 			Matches: false,
 		},
+		"failureObtainingFiles": {
+			PullContext: &pulltest.MockPullContext{
+				FilesErrValue: errors.New("can't get files"),
+			},
+			Matches: false,
+		},
+		"failureObtainingTeamMembership": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue:        "octocat",
+				TeamMemberErrValue: errors.New("can't get team membership"),
+			},
+			Matches: false,
+		},
 	}
 
 	for name, test := range tests {
@@ -161,3 +258,402 @@ func TestSignalsFailures(t *testing.T) {
 		})
 	}
 }
+
+func TestSignalsHeadBranches(t *testing.T) {
+	signals := Signals{
+		HeadBranches: []string{"renovate/*", "hotfix/**", "*:docs/*"},
+	}
+
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		Head    string
+		Matches bool
+		Reason  string
+	}{
+		"headMatchesGlob": {
+			Head:    "renovate/lodash-4.x",
+			Matches: true,
+			Reason:  `pull request head "renovate/lodash-4.x" matches a testlist head branch: "renovate/*"`,
+		},
+		"headMatchesDoubleStarGlob": {
+			Head:    "hotfix/1.2/crash",
+			Matches: true,
+			Reason:  `pull request head "hotfix/1.2/crash" matches a testlist head branch: "hotfix/**"`,
+		},
+		"headDoesNotMatchNestedBranch": {
+			Head:    "renovate/npm/lodash",
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"forkHeadDoesNotMatchUnqualifiedGlob": {
+			Head:    "mallory:renovate/lodash-4.x",
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"forkHeadMatchesQualifiedGlob": {
+			Head:    "octocat:docs/typo",
+			Matches: true,
+			Reason:  `pull request head "octocat:docs/typo" matches a testlist head branch: "*:docs/*"`,
+		},
+		"headDoesNotMatchQualifiedGlob": {
+			Head:    "docs/typo",
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{BranchName: test.Head}
+
+			matches, reason, err := signals.Matches(ctx, pc, "testlist")
+			require.NoError(t, err)
+			assert.Equal(t, test.Matches, matches)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+}
+
+func TestSignalsChangedPaths(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		Signals Signals
+		Files   []string
+		Matches bool
+		Reason  string
+	}{
+		"changedPathMatches": {
+			Signals: Signals{ChangedPaths: []string{"migrations/**", ".github/workflows/**"}},
+			Files:   []string{"main.go", ".github/workflows/ci.yml"},
+			Matches: true,
+			Reason:  `pull request changes ".github/workflows/ci.yml" matching a testlist path: ".github/workflows/**"`,
+		},
+		"changedPathDoesNotMatch": {
+			Signals: Signals{ChangedPaths: []string{"migrations/**"}},
+			Files:   []string{"main.go", "db/migrations.go"},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"onlyChangedPathsMatches": {
+			Signals: Signals{OnlyChangedPaths: []string{"docs/**", "*.md"}},
+			Files:   []string{"docs/index.md", "docs/img/logo.png", "README.md"},
+			Matches: true,
+			Reason:  `pull request only changes files matching the testlist paths: [docs/**,*.md]`,
+		},
+		"onlyChangedPathsDoesNotMatch": {
+			Signals: Signals{OnlyChangedPaths: []string{"docs/**"}},
+			Files:   []string{"docs/index.md", "main.go"},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"onlyChangedPathsWithoutFiles": {
+			Signals: Signals{OnlyChangedPaths: []string{"docs/**"}},
+			Files:   []string{},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{FilesValue: test.Files}
+
+			matches, reason, err := test.Signals.Matches(ctx, pc, "testlist")
+			require.NoError(t, err)
+			assert.Equal(t, test.Matches, matches)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+}
+
+func TestSignalsCommentMinPermission(t *testing.T) {
+	signals := Signals{
+		Comments:             []string{"bulldozer merge"},
+		CommentMinPermission: "write",
+	}
+
+	ctx := context.Background()
+	permissions := map[string]string{
+		"maintainer": "admin",
+		"developer":  "write",
+		"reporter":   "read",
+	}
+
+	tests := map[string]struct {
+		PullContext pull.Context
+		Matches     bool
+		Reason      string
+	}{
+		"commentFromWriter": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue:    []*pull.Comment{{Body: "bulldozer merge", Author: "developer"}},
+				PermissionValue: permissions,
+			},
+			Matches: true,
+			Reason:  `pull request has a testlist comment: "bulldozer merge"`,
+		},
+		"commentFromAdmin": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue:    []*pull.Comment{{Body: "bulldozer merge", Author: "maintainer"}},
+				PermissionValue: permissions,
+			},
+			Matches: true,
+			Reason:  `pull request has a testlist comment: "bulldozer merge"`,
+		},
+		"commentFromReader": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue:    []*pull.Comment{{Body: "bulldozer merge", Author: "reporter"}},
+				PermissionValue: permissions,
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"commentFromStranger": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue:    []*pull.Comment{{Body: "bulldozer merge", Author: "drive-by"}},
+				PermissionValue: permissions,
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"bodyFromReader": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue:     "reporter",
+				BodyValue:       "bulldozer merge",
+				PermissionValue: permissions,
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"bodyFromWriter": {
+			PullContext: &pulltest.MockPullContext{
+				AuthorValue:     "developer",
+				BodyValue:       "bulldozer merge",
+				PermissionValue: permissions,
+			},
+			Matches: true,
+			Reason:  `pull request body is a testlist comment: "bulldozer merge"`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matches, reason, err := signals.Matches(ctx, test.PullContext, "testlist")
+			require.NoError(t, err)
+			assert.Equal(t, test.Matches, matches)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+
+	t.Run("failureObtainingPermission", func(t *testing.T) {
+		pc := &pulltest.MockPullContext{
+			CommentValue:       []*pull.Comment{{Body: "bulldozer merge", Author: "developer"}},
+			PermissionErrValue: errors.New("can't get permission"),
+		}
+
+		matches, _, err := signals.Matches(ctx, pc, "testlist")
+		require.Error(t, err)
+		assert.False(t, matches, "expected pull request to not match, but it did")
+	})
+}
+
+func TestSignalsIgnoreStatusComments(t *testing.T) {
+	signals := Signals{
+		Comments:          []string{"bulldozer merge"},
+		CommentSubstrings: []string{":+1:"},
+	}
+
+	ctx := context.Background()
+	pc := &pulltest.MockPullContext{
+		CommentValue: []*pull.Comment{
+			{Body: StatusCommentMarker + "\n**bulldozer:** Not merging because of the whitelist\n\n" +
+				"| whitelist | fail | pull request does not match the whitelist: \"bulldozer merge\", \":+1:\" |"},
+		},
+	}
+
+	matches, reason, err := signals.Matches(ctx, pc, "testlist")
+	require.NoError(t, err)
+	assert.False(t, matches, "expected pull request to not match, but it did")
+	assert.Equal(t, "pull request does not match the testlist", reason)
+}
+
+func TestSignalsInvalidateCommentsOnPush(t *testing.T) {
+	signals := Signals{
+		Comments:                 []string{"bulldozer merge"},
+		InvalidateCommentsOnPush: true,
+	}
+
+	ctx := context.Background()
+	pushedAt := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	commits := []*pull.Commit{
+		{SHA: "first", CommittedAt: pushedAt.Add(-time.Hour)},
+		{SHA: "second", CommittedAt: pushedAt},
+	}
+
+	tests := map[string]struct {
+		PullContext pull.Context
+		Matches     bool
+		Reason      string
+	}{
+		"commentAfterPush": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue: []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(time.Minute)}},
+				CommitsValue: commits,
+			},
+			Matches: true,
+			Reason:  `pull request has a testlist comment: "bulldozer merge"`,
+		},
+		"commentBeforePush": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue: []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(-time.Minute)}},
+				CommitsValue: commits,
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"commentBeforePushOfBackdatedCommit": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue:  []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(-time.Minute)}},
+				CommitsValue:  []*pull.Commit{{SHA: "second", CommittedAt: pushedAt.Add(-24 * time.Hour)}},
+				PushedAtValue: pushedAt,
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"commentAfterPushOfBackdatedCommit": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue:  []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(time.Minute)}},
+				CommitsValue:  []*pull.Commit{{SHA: "second", CommittedAt: pushedAt.Add(-24 * time.Hour)}},
+				PushedAtValue: pushedAt,
+			},
+			Matches: true,
+			Reason:  `pull request has a testlist comment: "bulldozer merge"`,
+		},
+		"unknownPushTime": {
+			PullContext: &pulltest.MockPullContext{
+				CommentValue: []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(time.Minute)}},
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+		"body": {
+			PullContext: &pulltest.MockPullContext{
+				BodyValue:    "bulldozer merge",
+				CommitsValue: commits,
+			},
+			Matches: false,
+			Reason:  `pull request does not match the testlist`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matches, reason, err := signals.Matches(ctx, test.PullContext, "testlist")
+			require.NoError(t, err)
+			assert.Equal(t, test.Matches, matches)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+
+	t.Run("failureDeterminingPushTime", func(t *testing.T) {
+		pc := &pulltest.MockPullContext{
+			CommentValue:     []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(time.Minute)}},
+			PushedAtErrValue: errors.New("can't list check suites"),
+		}
+
+		matches, _, err := signals.Matches(ctx, pc, "testlist")
+		require.Error(t, err)
+		assert.False(t, matches, "expected pull request to not match, but it did")
+	})
+
+	t.Run("failureListingCommits", func(t *testing.T) {
+		pc := &pulltest.MockPullContext{
+			CommentValue:    []*pull.Comment{{Body: "bulldozer merge", CreatedAt: pushedAt.Add(time.Minute)}},
+			CommitsErrValue: errors.New("can't list commits"),
+		}
+
+		matches, _, err := signals.Matches(ctx, pc, "testlist")
+		require.Error(t, err)
+		assert.False(t, matches, "expected pull request to not match, but it did")
+	})
+}
+
+func TestSignalsInvalidPattern(t *testing.T) {
+	signals := Signals{
+		LabelPatterns: []string{"automerge:("},
+	}
+
+	require.Error(t, signals.validate())
+
+	matches, _, err := signals.Matches(context.Background(), &pulltest.MockPullContext{LabelValue: []string{"automerge:("}}, "testlist")
+	require.Error(t, err)
+	assert.False(t, matches, "expected pull request to not match, but it did")
+}
+
+func TestSignalsCompiledOnValidate(t *testing.T) {
+	signals := Signals{
+		LabelPatterns: []string{"^automerge:"},
+	}
+	require.NoError(t, signals.validate())
+	require.NotNil(t, signals.compiled)
+
+	// evaluations use the expressions compiled by validate
+	signals.LabelPatterns = []string{"automerge:("}
+	copied := signals
+	assert.Same(t, signals.compiled, copied.compiled)
+
+	matches, _, err := copied.Matches(context.Background(), &pulltest.MockPullContext{LabelValue: []string{"automerge: squash"}}, "testlist")
+	require.NoError(t, err)
+	assert.True(t, matches, "expected pull request to match, but it didn't")
+}
+
+func TestSignalsValidate(t *testing.T) {
+	tests := map[string]struct {
+		Signals Signals
+		Valid   bool
+	}{
+		"empty": {
+			Signals: Signals{},
+			Valid:   true,
+		},
+		"validAuthorFields": {
+			Signals: Signals{
+				AuthorTeams:        []string{"@org/platform", "org/release-managers"},
+				AuthorAssociations: []string{"member", "COLLABORATOR"},
+			},
+			Valid: true,
+		},
+		"teamWithoutOrganization": {
+			Signals: Signals{AuthorTeams: []string{"platform"}},
+		},
+		"unknownAssociation": {
+			Signals: Signals{AuthorAssociations: []string{"MAINTAINER"}},
+		},
+		"validCommentMinPermission": {
+			Signals: Signals{CommentMinPermission: "write"},
+			Valid:   true,
+		},
+		"unknownCommentMinPermission": {
+			Signals: Signals{CommentMinPermission: "triage"},
+		},
+		"noneCommentMinPermission": {
+			Signals: Signals{CommentMinPermission: "none"},
+		},
+		"invalidChangedPath": {
+			Signals: Signals{ChangedPaths: []string{"migrations/[0-9"}},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := test.Signals.validate()
+			if test.Valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
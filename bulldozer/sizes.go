@@ -0,0 +1,52 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+func (l *SizeLimits) Enabled() bool {
+	return l.MaxAdditions > 0 || l.MaxDeletions > 0 || l.MaxChangedFiles > 0 || l.MaxCommits > 0
+}
+
+// IsPRTooLarge returns true if the PR exceeds any of the size limits,
+// false otherwise. Additionally, a description of the reason will be returned.
+func IsPRTooLarge(ctx context.Context, pullCtx pull.Context, limits SizeLimits) (bool, string, error) {
+	size, err := pullCtx.Size(ctx)
+	if err != nil {
+		// size limits must always fail closed (too large on error)
+		return true, "unable to determine pull request size", err
+	}
+
+	for _, limit := range []struct {
+		name  string
+		value int
+		max   int
+	}{
+		{"additions", size.Additions, limits.MaxAdditions},
+		{"deletions", size.Deletions, limits.MaxDeletions},
+		{"changed files", size.ChangedFiles, limits.MaxChangedFiles},
+		{"commits", size.Commits, limits.MaxCommits},
+	} {
+		if limit.max > 0 && limit.value > limit.max {
+			return true, fmt.Sprintf("pull request has %d %s, more than the limit of %d", limit.value, limit.name, limit.max), nil
+		}
+	}
+	return false, "pull request is within the size limits", nil
+}
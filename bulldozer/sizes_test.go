@@ -0,0 +1,98 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ridge/bulldozer/pull"
+	"github.com/ridge/bulldozer/pull/pulltest"
+)
+
+func TestIsPRTooLarge(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		Limits   SizeLimits
+		Size     pull.Size
+		TooLarge bool
+		Reason   string
+	}{
+		"withinLimits": {
+			Limits:   SizeLimits{MaxAdditions: 100, MaxChangedFiles: 10},
+			Size:     pull.Size{Additions: 100, Deletions: 100, ChangedFiles: 10, Commits: 3},
+			TooLarge: false,
+			Reason:   "pull request is within the size limits",
+		},
+		"tooManyAdditions": {
+			Limits:   SizeLimits{MaxAdditions: 100},
+			Size:     pull.Size{Additions: 101},
+			TooLarge: true,
+			Reason:   "pull request has 101 additions, more than the limit of 100",
+		},
+		"tooManyDeletions": {
+			Limits:   SizeLimits{MaxDeletions: 100},
+			Size:     pull.Size{Deletions: 150},
+			TooLarge: true,
+			Reason:   "pull request has 150 deletions, more than the limit of 100",
+		},
+		"tooManyChangedFiles": {
+			Limits:   SizeLimits{MaxChangedFiles: 10},
+			Size:     pull.Size{ChangedFiles: 11},
+			TooLarge: true,
+			Reason:   "pull request has 11 changed files, more than the limit of 10",
+		},
+		"tooManyCommits": {
+			Limits:   SizeLimits{MaxAdditions: 100, MaxCommits: 20},
+			Size:     pull.Size{Additions: 10, Deletions: 10, ChangedFiles: 1, Commits: 30},
+			TooLarge: true,
+			Reason:   "pull request has 30 commits, more than the limit of 20",
+		},
+		"zeroDisablesLimit": {
+			Limits:   SizeLimits{MaxChangedFiles: 10},
+			Size:     pull.Size{Additions: 5000, Deletions: 5000, ChangedFiles: 1, Commits: 100},
+			TooLarge: false,
+			Reason:   "pull request is within the size limits",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			pc := &pulltest.MockPullContext{
+				SizeValue: &test.Size,
+			}
+
+			tooLarge, reason, err := IsPRTooLarge(ctx, pc, test.Limits)
+			require.NoError(t, err)
+			assert.Equal(t, test.TooLarge, tooLarge)
+			assert.Equal(t, test.Reason, reason)
+		})
+	}
+
+	t.Run("failureObtainingSize", func(t *testing.T) {
+		pc := &pulltest.MockPullContext{
+			SizeErrValue: errors.New("can't get size"),
+		}
+
+		tooLarge, _, err := IsPRTooLarge(ctx, pc, SizeLimits{MaxAdditions: 100})
+		require.Error(t, err)
+		assert.True(t, tooLarge)
+	})
+}
@@ -0,0 +1,402 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+// StatusConclusions maps the conclusions of completed status checks to the
+// result of the corresponding required status condition. Conclusions that
+// are missing use the default result.
+type StatusConclusions map[string]Result
+
+// statusConclusionNames lists the conclusions of check runs and the states
+// of completed commit statuses
+var statusConclusionNames = []string{
+	"success",
+	"failure",
+	"error",
+	"neutral",
+	"skipped",
+	"cancelled",
+	"timed_out",
+	"action_required",
+	"stale",
+}
+
+var defaultStatusConclusions = StatusConclusions{
+	"success": Pass,
+	"stale":   Pending,
+}
+
+var resultRanks = map[Result]int{
+	Fail:    0,
+	Pending: 1,
+	Pass:    2,
+}
+
+func (c StatusConclusions) validate() error {
+	conclusions := make([]string, 0, len(c))
+	for conclusion := range c {
+		conclusions = append(conclusions, conclusion)
+	}
+	sort.Strings(conclusions)
+
+	for _, conclusion := range conclusions {
+		if !containsFold(statusConclusionNames, conclusion) {
+			return errors.Errorf("invalid conclusion %q, expected one of %s", conclusion, strings.Join(statusConclusionNames, ", "))
+		}
+		if _, ok := resultRanks[c[conclusion]]; !ok {
+			return errors.Errorf("invalid result %q for conclusion %q, expected one of pass, fail, pending", c[conclusion], conclusion)
+		}
+	}
+	return nil
+}
+
+func (c StatusConclusions) result(conclusion string) Result {
+	for k, result := range c {
+		if strings.EqualFold(k, conclusion) {
+			return result
+		}
+	}
+	if result, ok := defaultStatusConclusions[conclusion]; ok {
+		return result
+	}
+	return Fail
+}
+
+// RequiredStatus identifies the status checks required for merging or
+// updating a pull request. In configuration files, it is either the name of
+// a status check or a mapping with exactly one of the keys "name", "pattern",
+// "regex" or "any_of", optionally restricted to the status checks of an app or
+// a creator.
+type RequiredStatus struct {
+	// Name requires the status check with this name to pass.
+	Name string `yaml:"name"`
+
+	// Pattern is a glob that requires at least one status check to match,
+	// and all status checks that match to pass.
+	Pattern string `yaml:"pattern"`
+
+	// Regex is like Pattern, using a regular expression instead of a glob.
+	Regex string `yaml:"regex"`
+
+	// AnyOf requires at least one of the alternatives to be satisfied.
+	AnyOf []RequiredStatus `yaml:"any_of"`
+
+	// AppID restricts the status checks to the check runs created by the
+	// GitHub App with this ID, ignoring status checks of the same name from
+	// other sources.
+	AppID int64 `yaml:"app_id"`
+
+	// Creator restricts the status checks to those created by the user with
+	// this login. Check runs are created by the bot user of their app, like
+	// "github-actions[bot]".
+	Creator string `yaml:"creator"`
+}
+
+func (r *RequiredStatus) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var name string
+	if err := unmarshal(&name); err == nil {
+		*r = RequiredStatus{Name: name}
+		return nil
+	}
+
+	type plain RequiredStatus
+	return unmarshal((*plain)(r))
+}
+
+// String returns a description of the required status checks, which is also
+// the name of the corresponding condition.
+func (r RequiredStatus) String() string {
+	switch {
+	case r.AppID != 0:
+		return fmt.Sprintf("%s from app %d", r.unbound(), r.AppID)
+	case r.Creator != "":
+		return fmt.Sprintf("%s created by %q", r.unbound(), r.Creator)
+	case r.Pattern != "":
+		return fmt.Sprintf("statuses matching %q", r.Pattern)
+	case r.Regex != "":
+		return fmt.Sprintf("statuses matching regex %q", r.Regex)
+	case len(r.AnyOf) > 0:
+		alternatives := make([]string, len(r.AnyOf))
+		for i, alt := range r.AnyOf {
+			alternatives[i] = alt.String()
+		}
+		return fmt.Sprintf("any of [%s]", strings.Join(alternatives, ", "))
+	default:
+		return fmt.Sprintf("status %q", r.Name)
+	}
+}
+
+func (r RequiredStatus) validate() error {
+	set := 0
+	for _, field := range []bool{r.Name != "", r.Pattern != "", r.Regex != "", len(r.AnyOf) > 0} {
+		if field {
+			set++
+		}
+	}
+	if set != 1 {
+		return errors.New("required status must have exactly one of a name, pattern, regex or any_of")
+	}
+	if r.AppID != 0 && r.Creator != "" {
+		return errors.New("required status must not have both an app_id and a creator")
+	}
+	if len(r.AnyOf) > 0 && (r.AppID != 0 || r.Creator != "") {
+		return errors.New("app_id and creator must be set on the alternatives of any_of")
+	}
+
+	if _, err := r.matcher(); err != nil {
+		return err
+	}
+	for _, alt := range r.AnyOf {
+		if err := alt.validate(); err != nil {
+			return errors.Wrapf(err, "invalid alternative of %s", r)
+		}
+	}
+	return nil
+}
+
+// unbound returns the required status without its app or creator.
+func (r RequiredStatus) unbound() RequiredStatus {
+	r.AppID = 0
+	r.Creator = ""
+	return r
+}
+
+// fromSource returns the statuses created by the app or the creator of the
+// required status. If it has neither, only the newest status of each name
+// counts, so that an old success reported by one user cannot mask a newer
+// failure reported by another.
+func (r RequiredStatus) fromSource(statuses []*pull.Status) []*pull.Status {
+	if r.AppID == 0 && r.Creator == "" {
+		return newestStatuses(statuses)
+	}
+
+	var filtered []*pull.Status
+	for _, status := range statuses {
+		switch {
+		case r.AppID != 0 && status.AppID == r.AppID:
+			filtered = append(filtered, status)
+		case r.Creator != "" && strings.EqualFold(status.Creator, r.Creator):
+			filtered = append(filtered, status)
+		}
+	}
+	return filtered
+}
+
+// newestStatuses returns the most recently updated status of each name, in
+// order of first appearance. Of statuses updated at the same time, the first
+// one is kept.
+func newestStatuses(statuses []*pull.Status) []*pull.Status {
+	newest := make(map[string]int)
+	var result []*pull.Status
+	for _, status := range statuses {
+		i, ok := newest[status.Name]
+		switch {
+		case !ok:
+			newest[status.Name] = len(result)
+			result = append(result, status)
+		case status.UpdatedAt.After(result[i].UpdatedAt):
+			result[i] = status
+		}
+	}
+	return result
+}
+
+// matcher returns the expression matching the names of the status checks
+// selected by a pattern or a regex, or nil otherwise.
+func (r RequiredStatus) matcher() (*regexp.Regexp, error) {
+	switch {
+	case r.Pattern != "":
+		rx, err := compileGlob(r.Pattern)
+		return rx, errors.Wrapf(err, "invalid pattern %q", r.Pattern)
+	case r.Regex != "":
+		rx, err := regexp.Compile(r.Regex)
+		return rx, errors.Wrapf(err, "invalid regex %q", r.Regex)
+	default:
+		return nil, nil
+	}
+}
+
+// CheckRunID identifies the check runs with a name created by an app. An
+// AppID of 0 identifies the check runs with the name created by any app.
+type CheckRunID struct {
+	Name  string
+	AppID int64
+}
+
+// withoutCheckRun returns the statuses except for the check runs identified
+// by id. bulldozer's own check run stays in progress while the pull request
+// waits for its required statuses, so required statuses must not wait for it.
+func withoutCheckRun(statuses []*pull.Status, id CheckRunID) []*pull.Status {
+	if id.Name == "" {
+		return statuses
+	}
+
+	var filtered []*pull.Status
+	for _, status := range statuses {
+		isCheckRun := status.AppID != 0
+		if isCheckRun && status.Name == id.Name && (id.AppID == 0 || status.AppID == id.AppID) {
+			continue
+		}
+		filtered = append(filtered, status)
+	}
+	return filtered
+}
+
+// requiredStatusNames converts status check names to required statuses.
+func requiredStatusNames(names []string) []RequiredStatus {
+	required := make([]RequiredStatus, len(names))
+	for i, name := range names {
+		required[i] = RequiredStatus{Name: name}
+	}
+	return required
+}
+
+func validateRequiredStatuses(required []RequiredStatus) error {
+	for i, r := range required {
+		if err := r.validate(); err != nil {
+			return errors.Wrapf(err, "invalid entry %d", i)
+		}
+	}
+	return nil
+}
+
+// evaluateStatus returns the result of a required status condition for a
+// single status check. Descriptions of completed status checks that did not
+// pass are matched against the whitelist, if any; pending status checks are
+// never whitelisted.
+func evaluateStatus(status *pull.Status, conclusions StatusConclusions, whitelist []string) (Result, string) {
+	var result Result
+	var reason string
+
+	switch {
+	case status.Pending():
+		result, reason = Pending, "status check is pending"
+	case status.Conclusion == "success":
+		result, reason = conclusions.result(status.Conclusion), "status check succeeded"
+	default:
+		result, reason = conclusions.result(status.Conclusion), fmt.Sprintf("status check concluded %s", status.Conclusion)
+	}
+
+	if result != Pass && !status.Pending() && statusDescriptionWhitelisted(status.Description, whitelist) {
+		return Pass, fmt.Sprintf("status check description is whitelisted: %s", status.Description)
+	}
+	if result != Pass && status.Description != "" {
+		reason = fmt.Sprintf("%s: %s", reason, status.Description)
+	}
+	return result, reason
+}
+
+// evaluateNamedStatus returns the result of the status check with the given
+// name. If several status checks from the source of a bound required status
+// have the name, the best result among them is used.
+func evaluateNamedStatus(name string, statuses []*pull.Status, conclusions StatusConclusions, whitelist []string) (Result, string) {
+	result, reason := Pending, "status check has not been reported"
+	reported := false
+	for _, status := range statuses {
+		if status.Name != name {
+			continue
+		}
+
+		r, why := evaluateStatus(status, conclusions, whitelist)
+		if !reported || resultRanks[r] > resultRanks[result] {
+			result, reason = r, why
+		}
+		reported = true
+	}
+	return result, reason
+}
+
+// evaluate returns the result of the required status. Statuses selected by a
+// pattern or a regex must all pass, and alternatives are satisfied by the
+// best of them.
+func (r RequiredStatus) evaluate(statuses []*pull.Status, conclusions StatusConclusions, descriptionWhitelist map[string][]string) (Result, string, error) {
+	if len(r.AnyOf) > 0 {
+		var result Result
+		var reason string
+		for i, alt := range r.AnyOf {
+			altResult, altReason, err := alt.evaluate(statuses, conclusions, descriptionWhitelist)
+			if err != nil {
+				return Fail, "", err
+			}
+			if i == 0 || resultRanks[altResult] > resultRanks[result] {
+				result, reason = altResult, fmt.Sprintf("%s: %s", alt, altReason)
+			}
+		}
+		return result, reason, nil
+	}
+
+	statuses = r.fromSource(statuses)
+
+	rx, err := r.matcher()
+	if err != nil {
+		return Fail, "", err
+	}
+	if rx == nil {
+		result, reason := evaluateNamedStatus(r.Name, statuses, conclusions, descriptionWhitelist[r.Name])
+		return result, reason, nil
+	}
+
+	var names []string
+	seen := make(map[string]bool)
+	for _, status := range statuses {
+		if rx.MatchString(status.Name) && !seen[status.Name] {
+			seen[status.Name] = true
+			names = append(names, status.Name)
+		}
+	}
+	if len(names) == 0 {
+		return Pending, "no matching status check has been reported", nil
+	}
+
+	result, reason := Pass, fmt.Sprintf("all %d matching status checks passed", len(names))
+	for _, name := range names {
+		r, why := evaluateNamedStatus(name, statuses, conclusions, descriptionWhitelist[name])
+		if resultRanks[r] < resultRanks[result] {
+			result, reason = r, fmt.Sprintf("status %q: %s", name, why)
+		}
+	}
+	return result, reason, nil
+}
+
+// requiredStatusConditions adds a condition for each required status to the
+// decision.
+func requiredStatusConditions(decision *Decision, requiredStatuses []RequiredStatus, statuses []*pull.Status, conclusions StatusConclusions, descriptionWhitelist map[string][]string) error {
+	seen := make(map[string]bool)
+	for _, required := range requiredStatuses {
+		name := required.String()
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		result, reason, err := required.evaluate(statuses, conclusions, descriptionWhitelist)
+		if err != nil {
+			return errors.Wrapf(err, "failed to evaluate %s", name)
+		}
+		decision.add(name, result, reason)
+	}
+	return nil
+}
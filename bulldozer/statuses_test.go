@@ -0,0 +1,315 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bulldozer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"github.com/ridge/bulldozer/pull"
+)
+
+func TestRequiredStatusConditions(t *testing.T) {
+	conclusions := StatusConclusions{
+		"neutral":   Pass,
+		"skipped":   Pass,
+		"cancelled": Pending,
+	}
+
+	tests := map[string]struct {
+		Statuses    []*pull.Status
+		Conclusions StatusConclusions
+		Whitelist   map[string][]string
+		Condition   Condition
+	}{
+		"notReported": {
+			Condition: Condition{Name: `status "ci"`, Result: Pending, Reason: "status check has not been reported"},
+		},
+		"pending": {
+			Statuses:  []*pull.Status{{Name: "ci", Description: "in_progress"}},
+			Condition: Condition{Name: `status "ci"`, Result: Pending, Reason: "status check is pending: in_progress"},
+		},
+		"success": {
+			Statuses:  []*pull.Status{{Name: "ci", Conclusion: "success", Description: "All tests passed"}},
+			Condition: Condition{Name: `status "ci"`, Result: Pass, Reason: "status check succeeded"},
+		},
+		"failure": {
+			Statuses:  []*pull.Status{{Name: "ci", Conclusion: "failure", Description: "3 tests failed"}},
+			Condition: Condition{Name: `status "ci"`, Result: Fail, Reason: "status check concluded failure: 3 tests failed"},
+		},
+		"skippedByDefault": {
+			Statuses:  []*pull.Status{{Name: "ci", Conclusion: "skipped"}},
+			Condition: Condition{Name: `status "ci"`, Result: Fail, Reason: "status check concluded skipped"},
+		},
+		"staleByDefault": {
+			Statuses:  []*pull.Status{{Name: "ci", Conclusion: "stale"}},
+			Condition: Condition{Name: `status "ci"`, Result: Pending, Reason: "status check concluded stale"},
+		},
+		"skippedMapped": {
+			Statuses:    []*pull.Status{{Name: "ci", Conclusion: "skipped"}},
+			Conclusions: conclusions,
+			Condition:   Condition{Name: `status "ci"`, Result: Pass, Reason: "status check concluded skipped"},
+		},
+		"cancelledMapped": {
+			Statuses:    []*pull.Status{{Name: "ci", Conclusion: "cancelled"}},
+			Conclusions: conclusions,
+			Condition:   Condition{Name: `status "ci"`, Result: Pending, Reason: "status check concluded cancelled"},
+		},
+		"failureNotMapped": {
+			Statuses:    []*pull.Status{{Name: "ci", Conclusion: "failure"}},
+			Conclusions: conclusions,
+			Condition:   Condition{Name: `status "ci"`, Result: Fail, Reason: "status check concluded failure"},
+		},
+		"descriptionWhitelisted": {
+			Statuses:  []*pull.Status{{Name: "ci", Conclusion: "failure", Description: "0 failures, 2 pending"}},
+			Whitelist: map[string][]string{"ci": {"^0 failures"}},
+			Condition: Condition{Name: `status "ci"`, Result: Pass, Reason: "status check description is whitelisted: 0 failures, 2 pending"},
+		},
+		"pendingNotWhitelisted": {
+			Statuses:  []*pull.Status{{Name: "ci", Description: "0 failures, 2 pending"}},
+			Whitelist: map[string][]string{"ci": {"^0 failures"}},
+			Condition: Condition{Name: `status "ci"`, Result: Pending, Reason: "status check is pending: 0 failures, 2 pending"},
+		},
+		"newestOfDuplicates": {
+			Statuses: []*pull.Status{
+				{Name: "ci", Conclusion: "success", UpdatedAt: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)},
+				{Name: "ci", Conclusion: "failure", UpdatedAt: time.Date(2026, 10, 14, 12, 5, 0, 0, time.UTC)},
+				{Name: "ci", UpdatedAt: time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+			},
+			Condition: Condition{Name: `status "ci"`, Result: Fail, Reason: "status check concluded failure"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			decision := &Decision{}
+			err := requiredStatusConditions(decision, []RequiredStatus{{Name: "ci"}, {Name: "ci"}}, test.Statuses, test.Conclusions, test.Whitelist)
+			require.NoError(t, err)
+			assert.Equal(t, []Condition{test.Condition}, decision.Conditions)
+		})
+	}
+}
+
+func TestStatusConclusionsValidate(t *testing.T) {
+	assert.NoError(t, StatusConclusions{"neutral": Pass, "skipped": Pass, "cancelled": Pending}.validate())
+	assert.EqualError(t, StatusConclusions{"passed": Pass}.validate(),
+		`invalid conclusion "passed", expected one of success, failure, error, neutral, skipped, cancelled, timed_out, action_required, stale`)
+	assert.EqualError(t, StatusConclusions{"neutral": "ok"}.validate(),
+		`invalid result "ok" for conclusion "neutral", expected one of pass, fail, pending`)
+}
+
+func TestRequiredStatusPatterns(t *testing.T) {
+	statuses := []*pull.Status{
+		{Name: "build", Conclusion: "success"},
+		{Name: "test (ubuntu, 1.21)", Conclusion: "success"},
+		{Name: "test (ubuntu, 1.22)", Conclusion: "success"},
+		{Name: "test (windows, 1.22)", Description: "in_progress"},
+		{Name: "lint", Conclusion: "failure"},
+		{Name: "deploy/staging", Conclusion: "success"},
+	}
+
+	tests := map[string]struct {
+		Required  RequiredStatus
+		Condition Condition
+	}{
+		"allMatchesPass": {
+			Required:  RequiredStatus{Pattern: "test (ubuntu, *)"},
+			Condition: Condition{Name: `statuses matching "test (ubuntu, *)"`, Result: Pass, Reason: "all 2 matching status checks passed"},
+		},
+		"someMatchesPending": {
+			Required:  RequiredStatus{Pattern: "test *"},
+			Condition: Condition{Name: `statuses matching "test *"`, Result: Pending, Reason: `status "test (windows, 1.22)": status check is pending: in_progress`},
+		},
+		"noMatches": {
+			Required:  RequiredStatus{Pattern: "e2e *"},
+			Condition: Condition{Name: `statuses matching "e2e *"`, Result: Pending, Reason: "no matching status check has been reported"},
+		},
+		"globDoesNotCrossSlashes": {
+			Required:  RequiredStatus{Pattern: "deploy*"},
+			Condition: Condition{Name: `statuses matching "deploy*"`, Result: Pending, Reason: "no matching status check has been reported"},
+		},
+		"regex": {
+			Required:  RequiredStatus{Regex: `^(build|lint)$`},
+			Condition: Condition{Name: `statuses matching regex "^(build|lint)$"`, Result: Fail, Reason: `status "lint": status check concluded failure`},
+		},
+		"anyOfPasses": {
+			Required: RequiredStatus{AnyOf: []RequiredStatus{{Name: "lint"}, {Name: "build"}}},
+			Condition: Condition{
+				Name:   `any of [status "lint", status "build"]`,
+				Result: Pass,
+				Reason: `status "build": status check succeeded`,
+			},
+		},
+		"anyOfPending": {
+			Required: RequiredStatus{AnyOf: []RequiredStatus{{Name: "lint"}, {Pattern: "test (windows, *)"}}},
+			Condition: Condition{
+				Name:   `any of [status "lint", statuses matching "test (windows, *)"]`,
+				Result: Pending,
+				Reason: `statuses matching "test (windows, *)": status "test (windows, 1.22)": status check is pending: in_progress`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, test.Required.validate())
+
+			decision := &Decision{}
+			err := requiredStatusConditions(decision, []RequiredStatus{test.Required}, statuses, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []Condition{test.Condition}, decision.Conditions)
+		})
+	}
+}
+
+func TestRequiredStatusUnmarshal(t *testing.T) {
+	config := `
+- "ci/circleci: ete-tests"
+- pattern: "test (*)"
+- regex: "^lint"
+- any_of:
+    - "deploy"
+    - pattern: "deploy/*"
+- name: "ci/build"
+  app_id: 15368
+- pattern: "ci/*"
+  creator: "ci-bot"
+`
+
+	var required []RequiredStatus
+	require.NoError(t, yaml.UnmarshalStrict([]byte(config), &required))
+	assert.Equal(t, []RequiredStatus{
+		{Name: "ci/circleci: ete-tests"},
+		{Pattern: "test (*)"},
+		{Regex: "^lint"},
+		{AnyOf: []RequiredStatus{{Name: "deploy"}, {Pattern: "deploy/*"}}},
+		{Name: "ci/build", AppID: 15368},
+		{Pattern: "ci/*", Creator: "ci-bot"},
+	}, required)
+
+	assert.Error(t, yaml.UnmarshalStrict([]byte(`[{glob: "test *"}]`), &required), "expected unknown keys to be rejected")
+}
+
+func TestRequiredStatusValidate(t *testing.T) {
+	assert.EqualError(t, RequiredStatus{}.validate(), "required status must have exactly one of a name, pattern, regex or any_of")
+	assert.EqualError(t, RequiredStatus{Pattern: "a", Regex: "b"}.validate(), "required status must have exactly one of a name, pattern, regex or any_of")
+	assert.Error(t, RequiredStatus{Regex: "("}.validate())
+	assert.Error(t, RequiredStatus{AnyOf: []RequiredStatus{{Name: "a"}, {Regex: "("}}}.validate())
+	assert.EqualError(t, RequiredStatus{Name: "a", AppID: 1, Creator: "b"}.validate(), "required status must not have both an app_id and a creator")
+	assert.EqualError(t, RequiredStatus{AnyOf: []RequiredStatus{{Name: "a"}}, AppID: 1}.validate(), "app_id and creator must be set on the alternatives of any_of")
+}
+
+func TestRequiredStatusSources(t *testing.T) {
+	reported := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	statuses := []*pull.Status{
+		{Name: "ci/build", Conclusion: "failure", AppID: 15368, Creator: "github-actions[bot]", UpdatedAt: reported.Add(time.Minute)},
+		{Name: "ci/build", Conclusion: "success", Creator: "mallory", UpdatedAt: reported},
+		{Name: "ci/lint", Conclusion: "success", Creator: "ci-bot"},
+		{Name: "ci/test", Conclusion: "success", AppID: 42, Creator: "other-ci[bot]"},
+	}
+
+	tests := map[string]struct {
+		Required  RequiredStatus
+		Condition Condition
+	}{
+		"unboundUsesNewest": {
+			Required:  RequiredStatus{Name: "ci/build"},
+			Condition: Condition{Name: `status "ci/build"`, Result: Fail, Reason: "status check concluded failure"},
+		},
+		"unboundPatternUsesNewest": {
+			Required:  RequiredStatus{Pattern: "ci/*"},
+			Condition: Condition{Name: `statuses matching "ci/*"`, Result: Fail, Reason: `status "ci/build": status check concluded failure`},
+		},
+		"boundCreatorKeepsOwnStatus": {
+			Required:  RequiredStatus{Name: "ci/build", Creator: "mallory"},
+			Condition: Condition{Name: `status "ci/build" created by "mallory"`, Result: Pass, Reason: "status check succeeded"},
+		},
+		"appID": {
+			Required:  RequiredStatus{Name: "ci/build", AppID: 15368},
+			Condition: Condition{Name: `status "ci/build" from app 15368`, Result: Fail, Reason: "status check concluded failure"},
+		},
+		"creator": {
+			Required:  RequiredStatus{Name: "ci/lint", Creator: "CI-Bot"},
+			Condition: Condition{Name: `status "ci/lint" created by "CI-Bot"`, Result: Pass, Reason: "status check succeeded"},
+		},
+		"checkRunCreator": {
+			Required:  RequiredStatus{Name: "ci/build", Creator: "github-actions[bot]"},
+			Condition: Condition{Name: `status "ci/build" created by "github-actions[bot]"`, Result: Fail, Reason: "status check concluded failure"},
+		},
+		"otherSource": {
+			Required:  RequiredStatus{Name: "ci/lint", AppID: 15368},
+			Condition: Condition{Name: `status "ci/lint" from app 15368`, Result: Pending, Reason: "status check has not been reported"},
+		},
+		"pattern": {
+			Required:  RequiredStatus{Pattern: "ci/*", AppID: 42},
+			Condition: Condition{Name: `statuses matching "ci/*" from app 42`, Result: Pass, Reason: "all 1 matching status checks passed"},
+		},
+		"anyOf": {
+			Required: RequiredStatus{AnyOf: []RequiredStatus{{Name: "ci/build", AppID: 15368}, {Name: "ci/test", AppID: 42}}},
+			Condition: Condition{
+				Name:   `any of [status "ci/build" from app 15368, status "ci/test" from app 42]`,
+				Result: Pass,
+				Reason: `status "ci/test" from app 42: status check succeeded`,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.NoError(t, test.Required.validate())
+
+			decision := &Decision{}
+			err := requiredStatusConditions(decision, []RequiredStatus{test.Required}, statuses, nil, nil)
+			require.NoError(t, err)
+			assert.Equal(t, []Condition{test.Condition}, decision.Conditions)
+		})
+	}
+}
+
+func TestWithoutCheckRun(t *testing.T) {
+	build := &pull.Status{Name: "build", Conclusion: "success", AppID: 15368}
+	checkRun := &pull.Status{Name: "bulldozer", Description: "in_progress", AppID: 42}
+	otherAppCheckRun := &pull.Status{Name: "bulldozer", Description: "in_progress", AppID: 7}
+	commitStatus := &pull.Status{Name: "bulldozer", Description: "pending"}
+	statuses := []*pull.Status{build, checkRun, otherAppCheckRun, commitStatus}
+
+	tests := map[string]struct {
+		ID       CheckRunID
+		Statuses []*pull.Status
+	}{
+		"notReporting": {
+			ID:       CheckRunID{},
+			Statuses: statuses,
+		},
+		"ownApp": {
+			ID:       CheckRunID{Name: "bulldozer", AppID: 42},
+			Statuses: []*pull.Status{build, otherAppCheckRun, commitStatus},
+		},
+		"unknownApp": {
+			ID:       CheckRunID{Name: "bulldozer"},
+			Statuses: []*pull.Status{build, commitStatus},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Statuses, withoutCheckRun(statuses, test.ID))
+		})
+	}
+}
@@ -17,7 +17,6 @@ package bulldozer
 import (
 	"context"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/google/go-github/v43/github"
@@ -36,71 +35,98 @@ func statusDescriptionWhitelisted(description string, whitelist []string) bool {
 	return false
 }
 
-func ShouldUpdatePR(ctx context.Context, pullCtx pull.Context, updateConfig UpdateConfig) (bool, error) {
+// ShouldUpdatePR evaluates the conditions for updating the pull request. The
+// pull request should be updated if the returned decision passed.
+func ShouldUpdatePR(ctx context.Context, pullCtx pull.Context, updateConfig UpdateConfig) (*Decision, error) {
 	logger := zerolog.Ctx(ctx)
 
+	decision, err := evaluateUpdate(ctx, pullCtx, updateConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	if decision.Passed() {
+		logger.Info().Msgf("%s is deemed updateable", pullCtx.Locator())
+	} else {
+		logger.Debug().Msgf("%s is deemed not updateable because %s", pullCtx.Locator(), decision)
+	}
+	return decision, nil
+}
+
+func evaluateUpdate(ctx context.Context, pullCtx pull.Context, updateConfig UpdateConfig) (*Decision, error) {
+	decision := &Decision{}
+
 	if !updateConfig.DraftUpdate && pullCtx.IsDraft() {
-		logger.Debug().Msgf("%s is deemed not updateable because it is in draft stage", pullCtx.Locator())
-		return false, nil
+		decision.add("draft", Fail, "pull request is a draft")
+		return decision, nil
 	}
 
 	if !updateConfig.Blacklist.Enabled() && !updateConfig.Whitelist.Enabled() && len(updateConfig.RequiredStatuses) == 0 {
-		logger.Info().Msgf("%s is not updated due to missing update conditions in config file", pullCtx.Locator())
-		return false, nil
+		decision.add("update conditions", Fail, "configuration file has no update conditions")
+		return decision, nil
 	}
 
 	if updateConfig.Blacklist.Enabled() {
 		blacklisted, reason, err := IsPRBlacklisted(ctx, pullCtx, updateConfig.Blacklist)
 		if err != nil {
-			return false, errors.Wrapf(err, "failed to determine if pull request %s is blacklisted", pullCtx.Locator())
+			return nil, errors.Wrapf(err, "failed to determine if pull request %s is blacklisted", pullCtx.Locator())
 		}
 		if blacklisted {
-			logger.Debug().Msgf("%s is deemed not updateable because blacklisting is enabled and %s", pullCtx.Locator(), reason)
-			return false, nil
+			decision.add("blacklist", Fail, reason)
+			return decision, nil
 		}
+		decision.add("blacklist", Pass, reason)
 	}
 
 	if updateConfig.Whitelist.Enabled() {
 		whitelisted, reason, err := IsPRWhitelisted(ctx, pullCtx, updateConfig.Whitelist)
 		if err != nil {
-			return false, errors.Wrapf(err, "failed to determine if pull request %s is whitelisted", pullCtx.Locator())
+			return nil, errors.Wrapf(err, "failed to determine if pull request %s is whitelisted", pullCtx.Locator())
 		}
 		if !whitelisted {
-			logger.Debug().Msgf("%s is deemed not updateable because whitelisting is enabled and no whitelist signal detected", pullCtx.Locator())
-			return false, nil
+			decision.add("whitelist", Fail, reason)
+			return decision, nil
 		}
-
-		logger.Debug().Msgf("%s is whitelisted because whitelisting is enabled and %s", pullCtx.Locator(), reason)
+		decision.add("whitelist", Pass, reason)
 	}
 
-	if len(updateConfig.RequiredStatuses) > 0 {
-		successStatuses, failedStatuses, err := pullCtx.CurrentStatuses(ctx)
+	if updateConfig.SizeLimits.Enabled() {
+		tooLarge, reason, err := IsPRTooLarge(ctx, pullCtx, updateConfig.SizeLimits)
 		if err != nil {
-			return false, errors.Wrapf(err, "failed to determine currently successful status checks for pull request %s", pullCtx.Locator())
+			return nil, errors.Wrapf(err, "failed to determine if pull request %s is too large", pullCtx.Locator())
 		}
+		if tooLarge {
+			decision.add("size limits", Fail, reason)
+			return decision, nil
+		}
+		decision.add("size limits", Pass, reason)
+	}
 
-		for statusName, whitelist := range updateConfig.RequiredStatusesDescriptionWhitelist {
-			statusDescription, hasStatus := failedStatuses[statusName]
-			if !hasStatus {
-				continue
-			}
+	if updateConfig.Schedule.Enabled() {
+		scheduled, reason, err := IsPRScheduled(ctx, updateConfig.Schedule)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to determine if updating pull request %s is scheduled", pullCtx.Locator())
+		}
+		if scheduled {
+			decision.add("schedule", Pass, reason)
+		} else {
+			decision.add("schedule", Pending, reason)
+		}
+	}
 
-			if statusDescriptionWhitelisted(statusDescription, whitelist) {
-				successStatuses = append(successStatuses, statusName)
-			}
+	if len(updateConfig.RequiredStatuses) > 0 {
+		statuses, err := pullCtx.CurrentStatuses(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to determine current status checks for pull request %s", pullCtx.Locator())
 		}
 
-		unsatisfiedStatuses := setDifference(updateConfig.RequiredStatuses, successStatuses)
-		if len(unsatisfiedStatuses) > 0 {
-			logger.Debug().Msgf("%s is deemed not updateable because of unfulfilled status checks: [%s]", pullCtx.Locator(),
-				strings.Join(unsatisfiedStatuses, ","))
-			return false, nil
+		statuses = withoutCheckRun(statuses, updateConfig.ReportCheckRun)
+		if err := requiredStatusConditions(decision, updateConfig.RequiredStatuses, statuses, updateConfig.StatusConclusions, updateConfig.RequiredStatusesDescriptionWhitelist); err != nil {
+			return nil, errors.Wrapf(err, "failed to evaluate required status checks for pull request %s", pullCtx.Locator())
 		}
 	}
 
-	logger.Info().Msgf("%s is deemed updateable", pullCtx.Locator())
-
-	return true, nil
+	return decision, nil
 }
 
 func UpdatePR(ctx context.Context, pullCtx pull.Context, client *github.Client, updateConfig UpdateConfig, baseRef string) error {
@@ -58,9 +58,30 @@ func TestShouldUpdatePR(t *testing.T) {
 		require.NoError(t, err)
 		msg := fmt.Sprintf("case %d - blacklistEnabled=%t blacklisted=%t whitelistEnabled=%t whitelisted=%t -> doUpdate=%t",
 			ndx, testCase.blacklistEnabled, testCase.blacklisted, testCase.whitelistEnabled, testCase.whitelisted, testCase.expectingUpdate)
-		require.Equal(t, testCase.expectingUpdate, updating, msg)
+		require.Equal(t, testCase.expectingUpdate, updating.Passed(), msg)
 	}
 }
+func TestShouldUpdatePRSizeLimits(t *testing.T) {
+	ctx := context.Background()
+	updateConfig := UpdateConfig{
+		Whitelist:  Signals{Labels: []string{"whitelist"}},
+		SizeLimits: SizeLimits{MaxChangedFiles: 20},
+	}
+
+	pullCtx := &pulltest.MockPullContext{
+		LabelValue: []string{"whitelist"},
+		SizeValue:  &pull.Size{ChangedFiles: 20},
+	}
+	updating, err := ShouldUpdatePR(ctx, pullCtx, updateConfig)
+	require.NoError(t, err)
+	require.True(t, updating.Passed(), "pull request within the size limits should be updated")
+
+	pullCtx.SizeValue = &pull.Size{ChangedFiles: 21}
+	updating, err = ShouldUpdatePR(ctx, pullCtx, updateConfig)
+	require.NoError(t, err)
+	require.False(t, updating.Passed(), "pull request exceeding the size limits should not be updated")
+}
+
 func generateUpdateTestCase(blacklistable bool, blacklisted bool, whitelistable bool, whitelisted bool) (pull.Context, UpdateConfig) {
 	updateConfig := UpdateConfig{}
 	pullCtx := pulltest.MockPullContext{}
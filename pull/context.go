@@ -16,6 +16,7 @@ package pull
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/go-github/v43/github"
 )
@@ -46,6 +47,17 @@ type Context interface {
 	// Body returns the pull request body.
 	Body() string
 
+	// Author returns the login of the user who opened the pull request.
+	Author() string
+
+	// AuthorAssociation returns the relationship of the pull request author
+	// to the repository, such as "OWNER", "MEMBER" or "CONTRIBUTOR".
+	AuthorAssociation() string
+
+	// IsTeamMember returns true if the user is an active member of the team.
+	// The team is identified by "<organization>/<team slug>".
+	IsTeamMember(ctx context.Context, team, user string) (bool, error)
+
 	// Branches returns the base (also known as target) and head branch names
 	// of this pull request. Branches in this repository have no prefix, while
 	// branches in forks are prefixed with the owner of the fork and a colon.
@@ -56,6 +68,13 @@ type Context interface {
 	BaseRepo() string
 	BaseRef() string
 
+	// HeadSHA returns the SHA of the head commit of the pull request.
+	HeadSHA() string
+
+	// Size returns the number of added and deleted lines, changed files and
+	// commits of the pull request.
+	Size(ctx context.Context) (*Size, error)
+
 	// MergeState returns the current mergability of the pull request. It
 	// always returns the most up-to-date state possible.
 	MergeState(ctx context.Context) (*MergeState, error)
@@ -68,16 +87,38 @@ type Context interface {
 	// restricts the users or teams that have push access.
 	PushRestrictions(ctx context.Context) (bool, error)
 
-	// CurrentStatuses returns the names of all successful status checks for the pull request
-	// and names and descriptions of all failed status checks.
-	CurrentStatuses(ctx context.Context) ([]string, map[string]string, error)
+	// ReviewProtection returns the review requirements of the branch
+	// protection of the target branch of the pull request, or nil if it does
+	// not require reviews.
+	ReviewProtection(ctx context.Context) (*ReviewProtection, error)
+
+	// CurrentStatuses returns all commit statuses and check runs on the head
+	// commit of the pull request.
+	CurrentStatuses(ctx context.Context) ([]*Status, error)
 
 	// Comments lists all comments on the pull request.
-	Comments(ctx context.Context) ([]string, error)
+	Comments(ctx context.Context) ([]*Comment, error)
+
+	// Permission returns the permission level of the user on the repository:
+	// "admin", "write", "read" or "none".
+	Permission(ctx context.Context, user string) (string, error)
 
 	// Commits lists all commits on the pull request.
 	Commits(ctx context.Context) ([]*Commit, error)
 
+	// PushedAt returns the time the head commit of the pull request was
+	// pushed, as recorded by GitHub, or the zero time if GitHub has no record
+	// of it. Unlike commit dates, the pusher cannot choose this time.
+	PushedAt(ctx context.Context) (time.Time, error)
+
+	// Reviews lists all submitted reviews on the pull request, ordered from
+	// oldest to newest.
+	Reviews(ctx context.Context) ([]*Review, error)
+
+	// Files lists the paths of all files changed by the pull request. For
+	// renamed files, both the old and the new path are included.
+	Files(ctx context.Context) ([]string, error)
+
 	// Labels lists all labels on the pull request.
 	Labels(ctx context.Context) ([]string, error)
 
@@ -92,12 +133,99 @@ type Context interface {
 	IsDraft() bool
 }
 
+// ReviewProtection describes the reviews that branch protection requires
+// before a pull request can merge.
+type ReviewProtection struct {
+	// RequiredApprovals is the number of approvals from users with write
+	// access that are required.
+	RequiredApprovals int
+
+	// DismissStaleReviews is true if GitHub dismisses approvals when new
+	// commits are pushed.
+	DismissStaleReviews bool
+
+	// RequireCodeOwnerReviews is true if the code owners of changed files
+	// must approve the pull request.
+	RequireCodeOwnerReviews bool
+}
+
 type MergeState struct {
 	Closed    bool
 	Mergeable *bool
+
+	// Status is the merge state status of the pull request in the uppercase
+	// form used by the GraphQL API, like "CLEAN", "BLOCKED", "BEHIND" or
+	// "UNSTABLE". It is empty or "UNKNOWN" while GitHub computes it.
+	Status string
+}
+
+type Size struct {
+	Additions    int
+	Deletions    int
+	ChangedFiles int
+	Commits      int
+}
+
+// Status is a commit status or a check run.
+type Status struct {
+	Name string
+
+	// Conclusion is the state of a completed commit status ("success",
+	// "failure" or "error") or the conclusion of a completed check run
+	// ("success", "failure", "neutral", "skipped", "cancelled", "timed_out",
+	// "action_required" or "stale"). It is empty while the status is pending.
+	Conclusion string
+
+	// Description is the description of a commit status or the title of
+	// the output of a check run, or its status if it has no output.
+	Description string
+
+	// AppID is the ID of the GitHub App that created a check run. It is zero
+	// for commit statuses, as GitHub does not report their app.
+	AppID int64
+
+	// Creator is the login of the user that created a commit status, or the
+	// login of the bot user of the GitHub App that created a check run.
+	Creator string
+
+	// UpdatedAt is the time a commit status was created, or the time a check
+	// run completed or, while it is pending, started.
+	UpdatedAt time.Time
+}
+
+// Pending returns true if the status has not completed yet.
+func (s *Status) Pending() bool {
+	return s.Conclusion == ""
+}
+
+type Comment struct {
+	Body              string
+	Author            string
+	AuthorAssociation string
+	CreatedAt         time.Time
+}
+
+type ReviewState string
+
+const (
+	ReviewApproved         ReviewState = "APPROVED"
+	ReviewChangesRequested ReviewState = "CHANGES_REQUESTED"
+	ReviewCommented        ReviewState = "COMMENTED"
+	ReviewDismissed        ReviewState = "DISMISSED"
+)
+
+type Review struct {
+	Author      string
+	State       ReviewState
+	CommitSHA   string
+	SubmittedAt time.Time
 }
 
 type Commit struct {
 	SHA     string
 	Message string
+
+	// CommittedAt is the committer date of the commit, which changes when
+	// the commit is amended or rebased
+	CommittedAt time.Time
 }
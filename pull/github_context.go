@@ -18,6 +18,8 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v43/github"
 	"github.com/pkg/errors"
@@ -34,14 +36,23 @@ type GithubContext struct {
 	pr     *github.PullRequest
 
 	// cached fields
-	comments         []string
+	size             *Size
+	comments         []*Comment
 	commits          []*Commit
+	pushedAt         *time.Time
+	reviews          []*Review
+	files            []string
 	branchProtection *github.Protection
-	successStatuses  []string
-	failedStatuses   map[string]string
+	statuses         []*Status
+	teamMembership   map[string]bool
+	permissions      map[string]string
 }
 
 func NewGithubContext(client *github.Client, pr *github.PullRequest) Context {
+	return newGithubContext(client, pr)
+}
+
+func newGithubContext(client *github.Client, pr *github.PullRequest) *GithubContext {
 	return &GithubContext{
 		client: client,
 
@@ -76,6 +87,66 @@ func (ghc *GithubContext) Body() string {
 	return ghc.pr.GetBody()
 }
 
+func (ghc *GithubContext) Author() string {
+	return ghc.pr.GetUser().GetLogin()
+}
+
+func (ghc *GithubContext) AuthorAssociation() string {
+	return ghc.pr.GetAuthorAssociation()
+}
+
+func (ghc *GithubContext) IsTeamMember(ctx context.Context, team, user string) (bool, error) {
+	key := strings.ToLower(team + ":" + user)
+	if member, ok := ghc.teamMembership[key]; ok {
+		return member, nil
+	}
+
+	parts := strings.SplitN(team, "/", 2)
+	if len(parts) != 2 {
+		return false, errors.Errorf("invalid team %q, expected <organization>/<team slug>", team)
+	}
+
+	member := false
+	membership, _, err := ghc.client.Teams.GetTeamMembershipBySlug(ctx, parts[0], parts[1], user)
+	switch {
+	case err == nil:
+		member = membership.GetState() == "active"
+	case isNotFound(err):
+	default:
+		return false, errors.Wrapf(err, "failed to get membership of %s in team %s", user, team)
+	}
+
+	if ghc.teamMembership == nil {
+		ghc.teamMembership = make(map[string]bool)
+	}
+	ghc.teamMembership[key] = member
+	return member, nil
+}
+
+func (ghc *GithubContext) Size(ctx context.Context) (*Size, error) {
+	if ghc.size == nil {
+		pr := ghc.pr
+
+		// pull requests obtained from list endpoints do not include the
+		// size fields, fetch the full pull request in that case
+		if pr.Additions == nil || pr.Commits == nil {
+			fullPR, _, err := ghc.client.PullRequests.Get(ctx, ghc.owner, ghc.repo, ghc.number)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to get pull request size")
+			}
+			pr = fullPR
+		}
+
+		ghc.size = &Size{
+			Additions:    pr.GetAdditions(),
+			Deletions:    pr.GetDeletions(),
+			ChangedFiles: pr.GetChangedFiles(),
+			Commits:      pr.GetCommits(),
+		}
+	}
+	return ghc.size, nil
+}
+
 func (ghc *GithubContext) MergeState(ctx context.Context) (*MergeState, error) {
 	pr, _, err := ghc.client.PullRequests.Get(ctx, ghc.owner, ghc.repo, ghc.number)
 	if err != nil {
@@ -85,10 +156,11 @@ func (ghc *GithubContext) MergeState(ctx context.Context) (*MergeState, error) {
 	return &MergeState{
 		Closed:    pr.GetState() == "closed",
 		Mergeable: pr.Mergeable,
+		Status:    strings.ToUpper(pr.GetMergeableState()),
 	}, nil
 }
 
-func (ghc *GithubContext) Comments(ctx context.Context) ([]string, error) {
+func (ghc *GithubContext) Comments(ctx context.Context) ([]*Comment, error) {
 	if ghc.comments == nil {
 
 		prCommentOpts := &github.PullRequestListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100}}
@@ -99,7 +171,12 @@ func (ghc *GithubContext) Comments(ctx context.Context) ([]string, error) {
 			}
 
 			for _, c := range comments {
-				ghc.comments = append(ghc.comments, c.GetBody())
+				ghc.comments = append(ghc.comments, &Comment{
+					Body:              c.GetBody(),
+					Author:            c.GetUser().GetLogin(),
+					AuthorAssociation: c.GetAuthorAssociation(),
+					CreatedAt:         c.GetCreatedAt(),
+				})
 			}
 
 			if res.NextPage == 0 {
@@ -116,7 +193,12 @@ func (ghc *GithubContext) Comments(ctx context.Context) ([]string, error) {
 			}
 
 			for _, c := range comments {
-				ghc.comments = append(ghc.comments, c.GetBody())
+				ghc.comments = append(ghc.comments, &Comment{
+					Body:              c.GetBody(),
+					Author:            c.GetUser().GetLogin(),
+					AuthorAssociation: c.GetAuthorAssociation(),
+					CreatedAt:         c.GetCreatedAt(),
+				})
 			}
 
 			if res.NextPage == 0 {
@@ -129,6 +211,29 @@ func (ghc *GithubContext) Comments(ctx context.Context) ([]string, error) {
 	return ghc.comments, nil
 }
 
+func (ghc *GithubContext) Permission(ctx context.Context, user string) (string, error) {
+	key := strings.ToLower(user)
+	if permission, ok := ghc.permissions[key]; ok {
+		return permission, nil
+	}
+
+	permission := "none"
+	level, _, err := ghc.client.Repositories.GetPermissionLevel(ctx, ghc.owner, ghc.repo, user)
+	switch {
+	case err == nil:
+		permission = level.GetPermission()
+	case isNotFound(err):
+	default:
+		return "", errors.Wrapf(err, "failed to get permission level of %s on %s/%s", user, ghc.owner, ghc.repo)
+	}
+
+	if ghc.permissions == nil {
+		ghc.permissions = make(map[string]string)
+	}
+	ghc.permissions[key] = permission
+	return permission, nil
+}
+
 func (ghc *GithubContext) Commits(ctx context.Context) ([]*Commit, error) {
 	if ghc.commits == nil {
 		opts := &github.ListOptions{
@@ -152,14 +257,132 @@ func (ghc *GithubContext) Commits(ctx context.Context) ([]*Commit, error) {
 		ghc.commits = make([]*Commit, len(allCommits))
 		for i, c := range allCommits {
 			ghc.commits[i] = &Commit{
-				SHA:     c.GetCommit().GetSHA(),
-				Message: c.GetCommit().GetMessage(),
+				SHA:         c.GetSHA(),
+				Message:     c.GetCommit().GetMessage(),
+				CommittedAt: c.GetCommit().GetCommitter().GetDate(),
 			}
 		}
 	}
 	return ghc.commits, nil
 }
 
+// PushedAt approximates the push time with the creation of the first check
+// suite of the head commit, which GitHub creates when it receives the commit,
+// and the latest force push of the head commit to the head branch, which also
+// covers commits that GitHub received before. Force pushes of other commits
+// say nothing about when the head was pushed and are ignored.
+func (ghc *GithubContext) PushedAt(ctx context.Context) (time.Time, error) {
+	if ghc.pushedAt == nil {
+		var pushedAt time.Time
+
+		suiteOpts := &github.ListCheckSuiteOptions{
+			ListOptions: github.ListOptions{PerPage: 100},
+		}
+		for {
+			suites, resp, err := ghc.client.Checks.ListCheckSuitesForRef(ctx, ghc.owner, ghc.repo, ghc.HeadSHA(), suiteOpts)
+			if err != nil {
+				return time.Time{}, errors.Wrap(err, "failed to list check suites of the head commit")
+			}
+			for _, s := range suites.CheckSuites {
+				created := s.GetCreatedAt().Time
+				if pushedAt.IsZero() || created.Before(pushedAt) {
+					pushedAt = created
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			suiteOpts.Page = resp.NextPage
+		}
+
+		timelineOpts := &github.ListOptions{
+			PerPage: 100,
+		}
+		for {
+			events, resp, err := ghc.client.Issues.ListIssueTimeline(ctx, ghc.owner, ghc.repo, ghc.number, timelineOpts)
+			if err != nil {
+				return time.Time{}, errors.Wrap(err, "failed to list pull request timeline")
+			}
+			for _, e := range events {
+				if e.GetEvent() == "head_ref_force_pushed" && e.GetCommitID() == ghc.HeadSHA() && e.GetCreatedAt().After(pushedAt) {
+					pushedAt = e.GetCreatedAt()
+				}
+			}
+			if resp.NextPage == 0 {
+				break
+			}
+			timelineOpts.Page = resp.NextPage
+		}
+
+		ghc.pushedAt = &pushedAt
+	}
+	return *ghc.pushedAt, nil
+}
+
+func (ghc *GithubContext) Reviews(ctx context.Context) ([]*Review, error) {
+	if ghc.reviews == nil {
+		opts := &github.ListOptions{
+			PerPage: 100,
+		}
+
+		reviews := []*Review{}
+		for {
+			prReviews, resp, err := ghc.client.PullRequests.ListReviews(ctx, ghc.owner, ghc.repo, ghc.number, opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list pull request reviews")
+			}
+
+			for _, r := range prReviews {
+				reviews = append(reviews, &Review{
+					Author:      r.GetUser().GetLogin(),
+					State:       ReviewState(r.GetState()),
+					CommitSHA:   r.GetCommitID(),
+					SubmittedAt: r.GetSubmittedAt(),
+				})
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+
+		ghc.reviews = reviews
+	}
+	return ghc.reviews, nil
+}
+
+func (ghc *GithubContext) Files(ctx context.Context) ([]string, error) {
+	if ghc.files == nil {
+		opts := &github.ListOptions{
+			PerPage: 100,
+		}
+
+		files := []string{}
+		for {
+			commitFiles, resp, err := ghc.client.PullRequests.ListFiles(ctx, ghc.owner, ghc.repo, ghc.number, opts)
+			if err != nil {
+				return nil, errors.Wrap(err, "failed to list pull request files")
+			}
+
+			for _, f := range commitFiles {
+				files = append(files, f.GetFilename())
+				if f.GetPreviousFilename() != "" {
+					files = append(files, f.GetPreviousFilename())
+				}
+			}
+
+			if resp.NextPage == 0 {
+				break
+			}
+			opts.Page = resp.NextPage
+		}
+
+		ghc.files = files
+	}
+	return ghc.files, nil
+}
+
 func (ghc *GithubContext) RequiredStatuses(ctx context.Context) ([]string, error) {
 	if ghc.branchProtection == nil {
 		if err := ghc.loadBranchProtection(ctx); err != nil {
@@ -184,6 +407,22 @@ func (ghc *GithubContext) PushRestrictions(ctx context.Context) (bool, error) {
 	return false, nil
 }
 
+func (ghc *GithubContext) ReviewProtection(ctx context.Context) (*ReviewProtection, error) {
+	if ghc.branchProtection == nil {
+		if err := ghc.loadBranchProtection(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if r := ghc.branchProtection.GetRequiredPullRequestReviews(); r != nil {
+		return &ReviewProtection{
+			RequiredApprovals:       r.RequiredApprovingReviewCount,
+			DismissStaleReviews:     r.DismissStaleReviews,
+			RequireCodeOwnerReviews: r.RequireCodeOwnerReviews,
+		}, nil
+	}
+	return nil, nil
+}
+
 func (ghc *GithubContext) loadBranchProtection(ctx context.Context) error {
 	protection, _, err := ghc.client.Repositories.GetBranchProtection(ctx, ghc.owner, ghc.repo, ghc.pr.GetBase().GetRef())
 	if err != nil {
@@ -202,58 +441,142 @@ func isNotFound(err error) bool {
 	return ok && rerr.Response.StatusCode == http.StatusNotFound
 }
 
-func (ghc *GithubContext) CurrentStatuses(ctx context.Context) ([]string, map[string]string, error) {
-	if ghc.successStatuses == nil {
-		opts := &github.ListOptions{PerPage: 100}
-		var successStatuses []string
-		failedStatuses := map[string]string{}
+func (ghc *GithubContext) CurrentStatuses(ctx context.Context) ([]*Status, error) {
+	if ghc.statuses == nil {
+		statuses, err := CommitStatuses(ctx, ghc.client, ghc.owner, ghc.repo, ghc.pr.GetHead().GetSHA())
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get statuses of %s", ghc.Locator())
+		}
+		ghc.statuses = statuses
+	}
 
-		for {
-			combinedStatus, res, err := ghc.client.Repositories.GetCombinedStatus(ctx, ghc.owner, ghc.repo, ghc.pr.GetHead().GetSHA(), opts)
-			if err != nil {
-				return nil, nil, errors.Wrapf(err, "cannot get combined status for SHA %s on %s", ghc.pr.GetHead().GetSHA(), ghc.Locator())
-			}
+	return ghc.statuses, nil
+}
 
-			for _, s := range combinedStatus.Statuses {
-				if s.GetState() == "success" {
-					successStatuses = append(successStatuses, s.GetContext())
-				} else {
-					failedStatuses[s.GetContext()] = s.GetDescription()
-				}
-			}
+// CommitStatuses returns all commit statuses and check runs on a commit.
+func CommitStatuses(ctx context.Context, client *github.Client, owner, repo, sha string) ([]*Status, error) {
+	opts := &github.ListOptions{PerPage: 100}
+	statuses := []*Status{}
 
-			if res.NextPage == 0 {
-				break
-			}
-			opts.Page = res.NextPage
+	var allStatuses []*github.RepoStatus
+	for {
+		repoStatuses, res, err := client.Repositories.ListStatuses(ctx, owner, repo, sha, opts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get statuses for SHA %s", sha)
 		}
+		allStatuses = append(allStatuses, repoStatuses...)
 
-		checkOpts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
-		for {
-			checkRuns, res, err := ghc.client.Checks.ListCheckRunsForRef(ctx, ghc.owner, ghc.repo, ghc.pr.GetHead().GetSHA(), checkOpts)
-			if err != nil {
-				return nil, nil, errors.Wrapf(err, "cannot get check runs for SHA %s on %s", ghc.pr.GetHead().GetSHA(), ghc.Locator())
-			}
+		if res.NextPage == 0 {
+			break
+		}
+		opts.Page = res.NextPage
+	}
 
-			for _, s := range checkRuns.CheckRuns {
-				if s.GetConclusion() == "success" {
-					successStatuses = append(successStatuses, s.GetName())
-				} else {
-					failedStatuses[s.GetName()] = s.GetStatus()
-				}
-			}
+	for _, s := range latestCommitStatuses(allStatuses) {
+		status := &Status{
+			Name:        s.GetContext(),
+			Description: s.GetDescription(),
+			Creator:     s.GetCreator().GetLogin(),
+			UpdatedAt:   s.GetCreatedAt(),
+		}
+		if s.GetState() != "pending" {
+			status.Conclusion = s.GetState()
+		}
+		statuses = append(statuses, status)
+	}
 
-			if res.NextPage == 0 {
-				break
-			}
-			checkOpts.Page = res.NextPage
+	var allCheckRuns []*github.CheckRun
+	checkOpts := &github.ListCheckRunsOptions{ListOptions: github.ListOptions{PerPage: 100}}
+	for {
+		checkRuns, res, err := client.Checks.ListCheckRunsForRef(ctx, owner, repo, sha, checkOpts)
+		if err != nil {
+			return nil, errors.Wrapf(err, "cannot get check runs for SHA %s", sha)
+		}
+		allCheckRuns = append(allCheckRuns, checkRuns.CheckRuns...)
+
+		if res.NextPage == 0 {
+			break
+		}
+		checkOpts.Page = res.NextPage
+	}
+
+	for _, s := range latestCheckRuns(allCheckRuns) {
+		status := &Status{
+			Name:        s.GetName(),
+			Description: s.GetOutput().GetTitle(),
+			AppID:       s.GetApp().GetID(),
+			UpdatedAt:   s.GetStartedAt().Time,
 		}
+		if slug := s.GetApp().GetSlug(); slug != "" {
+			status.Creator = slug + "[bot]"
+		}
+		if status.Description == "" {
+			status.Description = s.GetStatus()
+		}
+		if s.GetStatus() == "completed" {
+			status.Conclusion = s.GetConclusion()
+			status.UpdatedAt = s.GetCompletedAt().Time
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
 
-		ghc.successStatuses = successStatuses
-		ghc.failedStatuses = failedStatuses
+// latestCommitStatuses returns the most recent commit status for each
+// context and creator, so that required statuses bound to a creator are not
+// replaced by the status of the same context created by another user. GitHub
+// lists statuses in reverse chronological order.
+func latestCommitStatuses(statuses []*github.RepoStatus) []*github.RepoStatus {
+	type key struct {
+		context string
+		creator string
 	}
 
-	return ghc.successStatuses, ghc.failedStatuses, nil
+	seen := make(map[key]bool)
+	var result []*github.RepoStatus
+	for _, s := range statuses {
+		k := key{context: s.GetContext(), creator: s.GetCreator().GetLogin()}
+		if !seen[k] {
+			seen[k] = true
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// latestCheckRuns returns the most recent check run for each app and check
+// name, so that re-running a check replaces the result of earlier runs.
+func latestCheckRuns(runs []*github.CheckRun) []*github.CheckRun {
+	type key struct {
+		appID int64
+		name  string
+	}
+
+	latest := make(map[key]int)
+	var result []*github.CheckRun
+	for _, run := range runs {
+		k := key{appID: run.GetApp().GetID(), name: run.GetName()}
+		i, ok := latest[k]
+		switch {
+		case !ok:
+			latest[k] = len(result)
+			result = append(result, run)
+		case isNewerCheckRun(run, result[i]):
+			result[i] = run
+		}
+	}
+	return result
+}
+
+// isNewerCheckRun returns true if run a started after run b. Runs that
+// started at the same time are ordered by ID, which increases over time.
+func isNewerCheckRun(a, b *github.CheckRun) bool {
+	aStarted, bStarted := a.GetStartedAt().Time, b.GetStartedAt().Time
+	if !aStarted.Equal(bStarted) {
+		return aStarted.After(bStarted)
+	}
+	return a.GetID() > b.GetID()
 }
 
 func (ghc *GithubContext) Branches() (base string, head string) {
@@ -281,6 +604,10 @@ func (ghc *GithubContext) BaseRef() string {
 	return ghc.pr.GetBase().GetRef()
 }
 
+func (ghc *GithubContext) HeadSHA() string {
+	return ghc.pr.GetHead().GetSHA()
+}
+
 func (ghc *GithubContext) Labels(ctx context.Context) ([]string, error) {
 	var labelNames []string
 	for _, label := range ghc.pr.Labels {
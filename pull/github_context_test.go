@@ -0,0 +1,171 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestCheckRuns(t *testing.T) {
+	start := time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+	run := func(id, appID int64, name string, started time.Duration, conclusion string) *github.CheckRun {
+		return &github.CheckRun{
+			ID:         int64Ptr(id),
+			Name:       stringPtr(name),
+			App:        &github.App{ID: int64Ptr(appID)},
+			StartedAt:  &github.Timestamp{Time: start.Add(started)},
+			Conclusion: stringPtr(conclusion),
+		}
+	}
+
+	runs := []*github.CheckRun{
+		run(3, 1, "test", time.Minute, "failure"),
+		run(1, 1, "build", 0, "success"),
+		run(2, 1, "test", 0, "success"),
+		run(4, 2, "test", 0, "success"),
+		run(6, 1, "build", 2*time.Minute, "failure"),
+		run(5, 1, "build", 2*time.Minute, "success"),
+	}
+
+	latest := latestCheckRuns(runs)
+
+	ids := make([]int64, len(latest))
+	for i, r := range latest {
+		ids[i] = r.GetID()
+	}
+	assert.Equal(t, []int64{3, 6, 4}, ids, "expected the newest run per app and name, in order of first appearance")
+}
+
+func TestLatestCommitStatuses(t *testing.T) {
+	status := func(id int64, context, creator, state string) *github.RepoStatus {
+		return &github.RepoStatus{
+			ID:      int64Ptr(id),
+			Context: stringPtr(context),
+			State:   stringPtr(state),
+			Creator: &github.User{Login: stringPtr(creator)},
+		}
+	}
+
+	// newest first, as listed by GitHub
+	statuses := []*github.RepoStatus{
+		status(5, "ci/build", "mallory", "success"),
+		status(4, "ci/build", "ci-app[bot]", "failure"),
+		status(3, "ci/build", "ci-app[bot]", "pending"),
+		status(2, "ci/lint", "ci-app[bot]", "success"),
+		status(1, "ci/build", "mallory", "failure"),
+	}
+
+	latest := latestCommitStatuses(statuses)
+
+	ids := make([]int64, len(latest))
+	for i, s := range latest {
+		ids[i] = s.GetID()
+	}
+	assert.Equal(t, []int64{5, 4, 2}, ids, "expected the newest status per context and creator")
+}
+
+func TestCommits(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/pulls/7/commits", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+		  {"sha": "f6374a30ec7a3f2dbf35b40ac984b64358ccd246", "commit": {"message": "First", "committer": {"date": "2026-10-14T11:00:00Z"}}},
+		  {"sha": "89aec3244253260261351047f0bf6d9b7626c4f6", "commit": {"message": "Second", "committer": {"date": "2026-10-14T12:00:00Z"}}}
+		]`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	commits, err := NewGithubContext(client, testPullRequest("89aec3244253260261351047f0bf6d9b7626c4f6")).Commits(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []*Commit{
+		{SHA: "f6374a30ec7a3f2dbf35b40ac984b64358ccd246", Message: "First", CommittedAt: time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		{SHA: "89aec3244253260261351047f0bf6d9b7626c4f6", Message: "Second", CommittedAt: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)},
+	}, commits)
+}
+
+func testPullRequest(head string) *github.PullRequest {
+	return &github.PullRequest{
+		Number: github.Int(7),
+		Head:   &github.PullRequestBranch{SHA: github.String(head)},
+		Base: &github.PullRequestBranch{
+			Ref:  github.String("main"),
+			Repo: &github.Repository{Name: github.String("repo"), Owner: &github.User{Login: github.String("owner")}},
+		},
+	}
+}
+
+func TestPushedAt(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/abc/check-suites", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 2, "check_suites": [
+		  {"id": 2, "created_at": "2026-10-14T12:05:00Z"},
+		  {"id": 1, "created_at": "2026-10-14T12:00:00Z"}
+		]}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+		  {"event": "committed"},
+		  {"event": "head_ref_force_pushed", "commit_id": "abc", "created_at": "2026-10-14T11:00:00Z"}
+		]`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	pushedAt, err := NewGithubContext(client, testPullRequest("abc")).PushedAt(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), pushedAt.UTC(), "expected the creation of the first check suite")
+}
+
+func TestPushedAtForcePush(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/commits/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 0, "check_suites": []}`)
+	})
+	mux.HandleFunc("/repos/owner/repo/issues/7/timeline", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `[
+		  {"event": "head_ref_force_pushed", "commit_id": "abc", "created_at": "2026-10-14T11:00:00Z"},
+		  {"event": "head_ref_force_pushed", "commit_id": "def", "created_at": "2026-10-14T12:00:00Z"}
+		]`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	client := github.NewClient(nil)
+	client.BaseURL, _ = url.Parse(srv.URL + "/")
+
+	pushedAt, err := NewGithubContext(client, testPullRequest("def")).PushedAt(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC), pushedAt.UTC(), "expected the force push of the head commit")
+
+	pushedAt, err = NewGithubContext(client, testPullRequest("ghi")).PushedAt(context.Background())
+	require.NoError(t, err)
+	assert.True(t, pushedAt.IsZero(), "force pushes of other commits are ignored")
+}
@@ -0,0 +1,366 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pull
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/pkg/errors"
+	"github.com/shurcooL/githubv4"
+)
+
+// GraphQLContext is a Context implementation that loads the size, comments,
+// commits, reviews and statuses of a pull request with a single GraphQL
+// query, instead of paginating several REST endpoints. Labels are taken from
+// the pull request payload, like in GithubContext. Everything else, and any
+// list that does not fit in the first page of the query, is loaded by the
+// embedded GithubContext using the REST API.
+// A new instance must be created for each request.
+type GraphQLContext struct {
+	*GithubContext
+
+	v4client *githubv4.Client
+
+	// cached fields, the other results of the query are cached in the
+	// fields of the GithubContext
+	loaded bool
+}
+
+func NewGraphQLContext(client *github.Client, v4client *githubv4.Client, pr *github.PullRequest) Context {
+	return &GraphQLContext{
+		GithubContext: newGithubContext(client, pr),
+		v4client:      v4client,
+	}
+}
+
+type v4PageInfo struct {
+	HasNextPage bool
+}
+
+type v4Actor struct {
+	Login string
+}
+
+type v4Comment struct {
+	Body              string
+	Author            v4Actor
+	AuthorAssociation string
+	CreatedAt         time.Time
+}
+
+type v4Comments struct {
+	Nodes    []v4Comment
+	PageInfo v4PageInfo
+}
+
+type v4StatusContext struct {
+	StatusContext struct {
+		Context     string
+		State       string
+		Description string
+		Creator     v4Actor
+		CreatedAt   time.Time
+	} `graphql:"... on StatusContext"`
+
+	CheckRun struct {
+		Name        string
+		Status      string
+		Conclusion  string
+		Title       string
+		StartedAt   *time.Time
+		CompletedAt *time.Time
+		CheckSuite  struct {
+			App struct {
+				DatabaseID int64 `graphql:"databaseId"`
+				Slug       string
+			}
+		}
+	} `graphql:"... on CheckRun"`
+
+	Typename string `graphql:"__typename"`
+}
+
+type v4PullRequest struct {
+	Additions    int
+	Deletions    int
+	ChangedFiles int
+
+	Comments v4Comments `graphql:"comments(first: 100)"`
+
+	Commits struct {
+		TotalCount int
+		Nodes      []struct {
+			Commit struct {
+				Oid           string
+				Message       string
+				CommittedDate time.Time
+			}
+		}
+		PageInfo v4PageInfo
+	} `graphql:"commits(first: 100)"`
+
+	Reviews struct {
+		Nodes []struct {
+			Author      v4Actor
+			State       string
+			SubmittedAt *time.Time
+			Commit      struct {
+				Oid string
+			}
+			Comments v4Comments `graphql:"comments(first: 100)"`
+		}
+		PageInfo v4PageInfo
+	} `graphql:"reviews(first: 100)"`
+}
+
+type v4PullRequestQuery struct {
+	Repository struct {
+		PullRequest v4PullRequest `graphql:"pullRequest(number: $number)"`
+
+		Object struct {
+			Commit struct {
+				StatusCheckRollup struct {
+					Contexts struct {
+						Nodes    []v4StatusContext
+						PageInfo v4PageInfo
+					} `graphql:"contexts(first: 100)"`
+				}
+			} `graphql:"... on Commit"`
+		} `graphql:"object(oid: $sha)"`
+	} `graphql:"repository(owner: $owner, name: $name)"`
+}
+
+// load runs the query and caches its results. Lists with more than one page
+// are not cached, so that the GithubContext loads them completely.
+func (gqc *GraphQLContext) load(ctx context.Context) error {
+	if gqc.loaded {
+		return nil
+	}
+
+	var q v4PullRequestQuery
+	vars := map[string]interface{}{
+		"owner":  githubv4.String(gqc.owner),
+		"name":   githubv4.String(gqc.repo),
+		"number": githubv4.Int(gqc.number),
+		"sha":    githubv4.GitObjectID(gqc.HeadSHA()),
+	}
+	if err := gqc.v4client.Query(ctx, &q, vars); err != nil {
+		return errors.Wrapf(err, "failed to query pull request %s", gqc.Locator())
+	}
+
+	pr := q.Repository.PullRequest
+
+	if gqc.size == nil {
+		gqc.size = &Size{
+			Additions:    pr.Additions,
+			Deletions:    pr.Deletions,
+			ChangedFiles: pr.ChangedFiles,
+			Commits:      pr.Commits.TotalCount,
+		}
+	}
+
+	// review comments are nested in reviews, so all reviews are needed
+	complete := !pr.Comments.PageInfo.HasNextPage && !pr.Reviews.PageInfo.HasNextPage
+	for _, r := range pr.Reviews.Nodes {
+		complete = complete && !r.Comments.PageInfo.HasNextPage
+	}
+	if complete && gqc.comments == nil {
+		comments := []*Comment{}
+		for _, r := range pr.Reviews.Nodes {
+			for _, c := range r.Comments.Nodes {
+				comments = append(comments, commentFromV4(c))
+			}
+		}
+		for _, c := range pr.Comments.Nodes {
+			comments = append(comments, commentFromV4(c))
+		}
+		gqc.comments = comments
+	}
+
+	if !pr.Commits.PageInfo.HasNextPage && gqc.commits == nil {
+		commits := []*Commit{}
+		for _, c := range pr.Commits.Nodes {
+			commits = append(commits, &Commit{
+				SHA:         c.Commit.Oid,
+				Message:     c.Commit.Message,
+				CommittedAt: c.Commit.CommittedDate,
+			})
+		}
+		gqc.commits = commits
+	}
+
+	if !pr.Reviews.PageInfo.HasNextPage && gqc.reviews == nil {
+		reviews := []*Review{}
+		for _, r := range pr.Reviews.Nodes {
+			review := &Review{
+				Author:    r.Author.Login,
+				State:     ReviewState(r.State),
+				CommitSHA: r.Commit.Oid,
+			}
+			if r.SubmittedAt != nil {
+				review.SubmittedAt = *r.SubmittedAt
+			}
+			reviews = append(reviews, review)
+		}
+		gqc.reviews = reviews
+	}
+
+	// the rollup only has the latest commit status of each context, and the
+	// latest check run of each name and check suite
+	contexts := q.Repository.Object.Commit.StatusCheckRollup.Contexts
+	if !contexts.PageInfo.HasNextPage && gqc.statuses == nil {
+		statuses := []*Status{}
+		for _, node := range contexts.Nodes {
+			if status := statusFromV4(node); status != nil {
+				statuses = append(statuses, status)
+			}
+		}
+		gqc.statuses = statuses
+	}
+
+	gqc.loaded = true
+	return nil
+}
+
+func commentFromV4(c v4Comment) *Comment {
+	return &Comment{
+		Body:              c.Body,
+		Author:            c.Author.Login,
+		AuthorAssociation: c.AuthorAssociation,
+		CreatedAt:         c.CreatedAt,
+	}
+}
+
+// statusFromV4 converts a context of a status check rollup to a Status,
+// using the same lowercase values as the REST API.
+func statusFromV4(node v4StatusContext) *Status {
+	switch node.Typename {
+	case "StatusContext":
+		s := node.StatusContext
+		status := &Status{
+			Name:        s.Context,
+			Description: s.Description,
+			Creator:     s.Creator.Login,
+			UpdatedAt:   s.CreatedAt,
+		}
+		switch state := strings.ToLower(s.State); state {
+		case "pending", "expected":
+		default:
+			status.Conclusion = state
+		}
+		return status
+
+	case "CheckRun":
+		r := node.CheckRun
+		status := &Status{
+			Name:        r.Name,
+			Description: r.Title,
+			AppID:       r.CheckSuite.App.DatabaseID,
+		}
+		if slug := r.CheckSuite.App.Slug; slug != "" {
+			status.Creator = slug + "[bot]"
+		}
+		if status.Description == "" {
+			status.Description = strings.ToLower(r.Status)
+		}
+		if r.StartedAt != nil {
+			status.UpdatedAt = *r.StartedAt
+		}
+		if strings.EqualFold(r.Status, "completed") {
+			status.Conclusion = strings.ToLower(r.Conclusion)
+			if r.CompletedAt != nil {
+				status.UpdatedAt = *r.CompletedAt
+			}
+		}
+		return status
+
+	default:
+		return nil
+	}
+}
+
+func (gqc *GraphQLContext) Size(ctx context.Context) (*Size, error) {
+	if err := gqc.load(ctx); err != nil {
+		return nil, err
+	}
+	return gqc.GithubContext.Size(ctx)
+}
+
+func (gqc *GraphQLContext) MergeState(ctx context.Context) (*MergeState, error) {
+	var q struct {
+		Repository struct {
+			PullRequest struct {
+				State            string
+				MergeStateStatus string
+			} `graphql:"pullRequest(number: $number)"`
+		} `graphql:"repository(owner: $owner, name: $name)"`
+	}
+	vars := map[string]interface{}{
+		"owner":  githubv4.String(gqc.owner),
+		"name":   githubv4.String(gqc.repo),
+		"number": githubv4.Int(gqc.number),
+	}
+	if err := gqc.v4client.Query(ctx, &q, vars); err != nil {
+		return nil, errors.Wrap(err, "failed to get pull request merge state")
+	}
+
+	pr := q.Repository.PullRequest
+	state := &MergeState{
+		Closed: pr.State != "OPEN",
+		Status: pr.MergeStateStatus,
+	}
+	switch pr.MergeStateStatus {
+	case "UNKNOWN", "":
+	case "DIRTY":
+		state.Mergeable = github.Bool(false)
+	default:
+		state.Mergeable = github.Bool(true)
+	}
+	return state, nil
+}
+
+func (gqc *GraphQLContext) Comments(ctx context.Context) ([]*Comment, error) {
+	if err := gqc.load(ctx); err != nil {
+		return nil, err
+	}
+	return gqc.GithubContext.Comments(ctx)
+}
+
+func (gqc *GraphQLContext) Commits(ctx context.Context) ([]*Commit, error) {
+	if err := gqc.load(ctx); err != nil {
+		return nil, err
+	}
+	return gqc.GithubContext.Commits(ctx)
+}
+
+func (gqc *GraphQLContext) Reviews(ctx context.Context) ([]*Review, error) {
+	if err := gqc.load(ctx); err != nil {
+		return nil, err
+	}
+	return gqc.GithubContext.Reviews(ctx)
+}
+
+func (gqc *GraphQLContext) CurrentStatuses(ctx context.Context) ([]*Status, error) {
+	if err := gqc.load(ctx); err != nil {
+		return nil, err
+	}
+	return gqc.GithubContext.CurrentStatuses(ctx)
+}
+
+// type assertion
+var _ Context = &GraphQLContext{}
@@ -0,0 +1,183 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/shurcooL/githubv4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullRequestQueryResponse = `{"data": {"repository": {
+  "pullRequest": {
+    "additions": 10, "deletions": 2, "changedFiles": 3,
+    "comments": {
+      "nodes": [{"body": "/merge", "author": {"login": "alice"}, "authorAssociation": "MEMBER", "createdAt": "2026-10-14T12:00:00Z"}],
+      "pageInfo": {"hasNextPage": false}
+    },
+    "commits": {
+      "totalCount": 1,
+      "nodes": [{"commit": {"oid": "abc", "message": "Fix the build", "committedDate": "2026-10-14T11:00:00Z"}}],
+      "pageInfo": {"hasNextPage": false}
+    },
+    "reviews": {
+      "nodes": [{
+        "author": {"login": "bob"}, "state": "APPROVED", "submittedAt": "2026-10-14T12:30:00Z", "commit": {"oid": "abc"},
+        "comments": {"nodes": [{"body": "nit", "author": {"login": "bob"}, "authorAssociation": "MEMBER", "createdAt": "2026-10-14T12:29:00Z"}], "pageInfo": {"hasNextPage": false}}
+      }],
+      "pageInfo": {"hasNextPage": false}
+    }
+  },
+  "object": {"statusCheckRollup": {"contexts": {
+    "nodes": [
+      {"__typename": "StatusContext", "context": "ci/build", "state": "SUCCESS", "description": "passed", "creator": {"login": "ci-bot"}, "createdAt": "2026-10-14T11:10:00Z"},
+      {"__typename": "StatusContext", "context": "ci/deploy", "state": "EXPECTED", "description": "", "creator": null, "createdAt": "2026-10-14T11:00:00Z"},
+      {"__typename": "CheckRun", "name": "test", "status": "COMPLETED", "conclusion": "FAILURE", "title": "2 tests failed", "startedAt": "2026-10-14T11:01:00Z", "completedAt": "2026-10-14T11:20:00Z", "checkSuite": {"app": {"databaseId": 15368, "slug": "github-actions"}}},
+      {"__typename": "CheckRun", "name": "lint", "status": "IN_PROGRESS", "conclusion": null, "title": null, "startedAt": "2026-10-14T11:02:00Z", "completedAt": null, "checkSuite": {"app": {"databaseId": 15368, "slug": "github-actions"}}}
+    ],
+    "pageInfo": {"hasNextPage": false}
+  }}}
+}}}`
+
+func TestGraphQLContext(t *testing.T) {
+	ctx := context.Background()
+
+	queries := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, pullRequestQueryResponse)
+	}))
+	defer srv.Close()
+
+	pr := &github.PullRequest{
+		Number: github.Int(7),
+		Labels: []*github.Label{{Name: github.String("merge when ready")}},
+		Head:   &github.PullRequestBranch{SHA: github.String("abc")},
+		Base: &github.PullRequestBranch{
+			Ref:  github.String("main"),
+			Repo: &github.Repository{Name: github.String("repo"), Owner: &github.User{Login: github.String("owner")}},
+		},
+	}
+	pullCtx := NewGraphQLContext(github.NewClient(nil), githubv4.NewEnterpriseClient(srv.URL, srv.Client()), pr)
+
+	labels, err := pullCtx.Labels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"merge when ready"}, labels)
+	assert.Equal(t, 0, queries, "expected labels to be read from the pull request")
+
+	size, err := pullCtx.Size(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, &Size{Additions: 10, Deletions: 2, ChangedFiles: 3, Commits: 1}, size)
+
+	comments, err := pullCtx.Comments(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*Comment{
+		{Body: "nit", Author: "bob", AuthorAssociation: "MEMBER", CreatedAt: time.Date(2026, 10, 14, 12, 29, 0, 0, time.UTC)},
+		{Body: "/merge", Author: "alice", AuthorAssociation: "MEMBER", CreatedAt: time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)},
+	}, comments)
+
+	commits, err := pullCtx.Commits(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*Commit{
+		{SHA: "abc", Message: "Fix the build", CommittedAt: time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+	}, commits)
+
+	reviews, err := pullCtx.Reviews(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*Review{
+		{Author: "bob", State: ReviewApproved, CommitSHA: "abc", SubmittedAt: time.Date(2026, 10, 14, 12, 30, 0, 0, time.UTC)},
+	}, reviews)
+
+	statuses, err := pullCtx.CurrentStatuses(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []*Status{
+		{Name: "ci/build", Conclusion: "success", Description: "passed", Creator: "ci-bot", UpdatedAt: time.Date(2026, 10, 14, 11, 10, 0, 0, time.UTC)},
+		{Name: "ci/deploy", UpdatedAt: time.Date(2026, 10, 14, 11, 0, 0, 0, time.UTC)},
+		{Name: "test", Conclusion: "failure", Description: "2 tests failed", AppID: 15368, Creator: "github-actions[bot]", UpdatedAt: time.Date(2026, 10, 14, 11, 20, 0, 0, time.UTC)},
+		{Name: "lint", Description: "in_progress", AppID: 15368, Creator: "github-actions[bot]", UpdatedAt: time.Date(2026, 10, 14, 11, 2, 0, 0, time.UTC)},
+	}, statuses)
+
+	assert.Equal(t, 1, queries, "expected a single query for all data")
+}
+
+func TestGraphQLContextMergeState(t *testing.T) {
+	tests := map[string]struct {
+		State            string
+		MergeStateStatus string
+		MergeState       *MergeState
+	}{
+		"clean": {
+			State:            "OPEN",
+			MergeStateStatus: "CLEAN",
+			MergeState:       &MergeState{Mergeable: github.Bool(true), Status: "CLEAN"},
+		},
+		"blocked": {
+			State:            "OPEN",
+			MergeStateStatus: "BLOCKED",
+			MergeState:       &MergeState{Mergeable: github.Bool(true), Status: "BLOCKED"},
+		},
+		"behind": {
+			State:            "OPEN",
+			MergeStateStatus: "BEHIND",
+			MergeState:       &MergeState{Mergeable: github.Bool(true), Status: "BEHIND"},
+		},
+		"conflicting": {
+			State:            "OPEN",
+			MergeStateStatus: "DIRTY",
+			MergeState:       &MergeState{Mergeable: github.Bool(false), Status: "DIRTY"},
+		},
+		"unknown": {
+			State:            "OPEN",
+			MergeStateStatus: "UNKNOWN",
+			MergeState:       &MergeState{Status: "UNKNOWN"},
+		},
+		"merged": {
+			State:            "MERGED",
+			MergeStateStatus: "UNKNOWN",
+			MergeState:       &MergeState{Closed: true, Status: "UNKNOWN"},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"data": {"repository": {"pullRequest": {"state": %q, "mergeStateStatus": %q}}}}`, test.State, test.MergeStateStatus)
+			}))
+			defer srv.Close()
+
+			pr := &github.PullRequest{
+				Number: github.Int(7),
+				Base: &github.PullRequestBranch{
+					Repo: &github.Repository{Name: github.String("repo"), Owner: &github.User{Login: github.String("owner")}},
+				},
+			}
+			pullCtx := NewGraphQLContext(github.NewClient(nil), githubv4.NewEnterpriseClient(srv.URL, srv.Client()), pr)
+
+			state, err := pullCtx.MergeState(context.Background())
+			require.NoError(t, err)
+			assert.Equal(t, test.MergeState, state)
+		})
+	}
+}
@@ -16,6 +16,8 @@ package pulltest
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v43/github"
 	"github.com/ridge/bulldozer/pull"
@@ -35,30 +37,57 @@ type MockPullContext struct {
 	BodyValue    string
 	LocatorValue string
 
+	AuthorValue            string
+	AuthorAssociationValue string
+
+	// TeamMembersValue maps team names to the logins of their members
+	TeamMembersValue   map[string][]string
+	TeamMemberErrValue error
+
 	BranchBase string
 	BranchName string
 
+	HeadSHAValue string
+
+	SizeValue    *pull.Size
+	SizeErrValue error
+
 	MergeStateValue    *pull.MergeState
 	MergeStateErrValue error
 
 	LabelValue    []string
 	LabelErrValue error
 
-	CommentValue    []string
+	CommentValue    []*pull.Comment
 	CommentErrValue error
 
+	// PermissionValue maps user logins to their permission level
+	PermissionValue    map[string]string
+	PermissionErrValue error
+
 	CommitsValue    []*pull.Commit
 	CommitsErrValue error
 
+	PushedAtValue    time.Time
+	PushedAtErrValue error
+
+	ReviewsValue    []*pull.Review
+	ReviewsErrValue error
+
+	FilesValue    []string
+	FilesErrValue error
+
 	RequiredStatusesValue    []string
 	RequiredStatusesErrValue error
 
 	PushRestrictionsValue    bool
 	PushRestrictionsErrValue error
 
-	SuccessStatusesValue []string
-	FailureStatusesValue map[string]string
-	StatusesErrValue     error
+	ReviewProtectionValue    *pull.ReviewProtection
+	ReviewProtectionErrValue error
+
+	StatusesValue    []*pull.Status
+	StatusesErrValue error
 
 	IsTargetedValue    bool
 	IsTargetedErrValue error
@@ -93,6 +122,10 @@ func (c *MockPullContext) BaseRef() string {
 	return c.BaseRefValue
 }
 
+func (c *MockPullContext) HeadSHA() string {
+	return c.HeadSHAValue
+}
+
 func (c *MockPullContext) Locator() string {
 	if c.LocatorValue != "" {
 		return c.LocatorValue
@@ -108,22 +141,68 @@ func (c *MockPullContext) Body() string {
 	return c.BodyValue
 }
 
+func (c *MockPullContext) Author() string {
+	return c.AuthorValue
+}
+
+func (c *MockPullContext) AuthorAssociation() string {
+	return c.AuthorAssociationValue
+}
+
+func (c *MockPullContext) IsTeamMember(ctx context.Context, team, user string) (bool, error) {
+	if c.TeamMemberErrValue != nil {
+		return false, c.TeamMemberErrValue
+	}
+	for _, member := range c.TeamMembersValue[team] {
+		if strings.EqualFold(member, user) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func (c *MockPullContext) Branches() (base string, head string) {
 	return c.BranchBase, c.BranchName
 }
 
+func (c *MockPullContext) Size(ctx context.Context) (*pull.Size, error) {
+	return c.SizeValue, c.SizeErrValue
+}
+
 func (c *MockPullContext) MergeState(ctx context.Context) (*pull.MergeState, error) {
 	return c.MergeStateValue, c.MergeStateErrValue
 }
 
-func (c *MockPullContext) Comments(ctx context.Context) ([]string, error) {
+func (c *MockPullContext) Comments(ctx context.Context) ([]*pull.Comment, error) {
 	return c.CommentValue, c.CommentErrValue
 }
 
+func (c *MockPullContext) Permission(ctx context.Context, user string) (string, error) {
+	if c.PermissionErrValue != nil {
+		return "", c.PermissionErrValue
+	}
+	if permission, ok := c.PermissionValue[user]; ok {
+		return permission, nil
+	}
+	return "none", nil
+}
+
 func (c *MockPullContext) Commits(ctx context.Context) ([]*pull.Commit, error) {
 	return c.CommitsValue, c.CommitsErrValue
 }
 
+func (c *MockPullContext) PushedAt(ctx context.Context) (time.Time, error) {
+	return c.PushedAtValue, c.PushedAtErrValue
+}
+
+func (c *MockPullContext) Reviews(ctx context.Context) ([]*pull.Review, error) {
+	return c.ReviewsValue, c.ReviewsErrValue
+}
+
+func (c *MockPullContext) Files(ctx context.Context) ([]string, error) {
+	return c.FilesValue, c.FilesErrValue
+}
+
 func (c *MockPullContext) RequiredStatuses(ctx context.Context) ([]string, error) {
 	return c.RequiredStatusesValue, c.RequiredStatusesErrValue
 }
@@ -132,8 +211,12 @@ func (c *MockPullContext) PushRestrictions(ctx context.Context) (bool, error) {
 	return c.PushRestrictionsValue, c.PushRestrictionsErrValue
 }
 
-func (c *MockPullContext) CurrentStatuses(ctx context.Context) ([]string, map[string]string, error) {
-	return c.SuccessStatusesValue, c.FailureStatusesValue, c.StatusesErrValue
+func (c *MockPullContext) ReviewProtection(ctx context.Context) (*pull.ReviewProtection, error) {
+	return c.ReviewProtectionValue, c.ReviewProtectionErrValue
+}
+
+func (c *MockPullContext) CurrentStatuses(ctx context.Context) ([]*pull.Status, error) {
+	return c.StatusesValue, c.StatusesErrValue
 }
 
 func (c *MockPullContext) Labels(ctx context.Context) ([]string, error) {
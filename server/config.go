@@ -50,6 +50,19 @@ type Options struct {
 	ConfigurationPath        string            `yaml:"configuration_path"`
 	DefaultRepositoryConfig  *bulldozer.Config `yaml:"default_repository_config"`
 	PushRestrictionUserToken string            `yaml:"push_restriction_user_token"`
+
+	// Reporting selects how bulldozer reports its evaluation on pull
+	// requests: "check_run" (the default), "comment" or "none".
+	Reporting string `yaml:"reporting"`
+
+	// PullRequestAPI selects the GitHub API used to load information about
+	// pull requests: "rest" (the default) or "graphql".
+	PullRequestAPI string `yaml:"pull_request_api"`
+
+	// QueueAPIToken is the bearer token required by the /api/queue
+	// endpoint. If empty, the endpoint is disabled, as it lists the pull
+	// requests of all installations.
+	QueueAPIToken string `yaml:"queue_api_token"`
 }
 
 func ParseConfig(bytes []byte) (*Config, error) {
@@ -62,6 +75,9 @@ func ParseConfig(bytes []byte) (*Config, error) {
 	if v, ok := os.LookupEnv("BULLDOZER_PUSH_RESTRICTION_USER_TOKEN"); ok {
 		c.Options.PushRestrictionUserToken = v
 	}
+	if v, ok := os.LookupEnv("BULLDOZER_QUEUE_API_TOKEN"); ok {
+		c.Options.QueueAPIToken = v
+	}
 
 	return &c, nil
 }
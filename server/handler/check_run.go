@@ -21,8 +21,6 @@ import (
 	"github.com/google/go-github/v43/github"
 	"github.com/palantir/go-githubapp/githubapp"
 	"github.com/pkg/errors"
-
-	"github.com/ridge/bulldozer/pull"
 )
 
 type CheckRun struct {
@@ -45,6 +43,17 @@ func handleCheckRun(config *ServerConfig, event github.CheckRunEvent) {
 		logger.Debug().Msgf("Doing nothing since check_run action was %q instead of 'completed'", event.GetAction())
 	}
 
+	// reports update bulldozer's own check run, which must not trigger
+	// another evaluation
+	if run := event.GetCheckRun(); run.GetName() == CheckRunName && (config.AppID == 0 || run.GetApp().GetID() == config.AppID) {
+		logger.Debug().Msgf("Doing nothing since check_run %q was created by bulldozer", run.GetName())
+		return
+	}
+
+	if processBatchStatus(ctx, config, installationID, repo.GetOwner().GetLogin(), repo.GetName(), event.GetCheckRun().GetHeadSHA()) {
+		return
+	}
+
 	client, err := config.ClientCreator.NewInstallationClient(installationID)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to instantiate github client")
@@ -67,10 +76,14 @@ func handleCheckRun(config *ServerConfig, event github.CheckRunEvent) {
 			logger.Error().Err(err).Msgf("failed to fetch PR number %q for CheckRun", pr.GetNumber())
 			continue
 		}
-		pullCtx := pull.NewGithubContext(client, fullPR)
+		pullCtx, err := config.NewPullContext(installationID, client, fullPR)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to instantiate pull request context")
+			continue
+		}
 
 		logger := logger.With().Int(githubapp.LogKeyPRNum, pr.GetNumber()).Logger()
-		if err := ProcessPullRequest(logger.WithContext(ctx), config, pullCtx, client, fullPR.GetBase().GetRef()); err != nil {
+		if err := ProcessPullRequest(logger.WithContext(ctx), config, installationID, pullCtx, client, fullPR.GetBase().GetRef()); err != nil {
 			logger.Error().Err(err).Msg("Error processing pull request")
 		}
 	}
@@ -22,7 +22,7 @@ import (
 	"github.com/palantir/go-githubapp/githubapp"
 	"github.com/pkg/errors"
 
-	"github.com/ridge/bulldozer/pull"
+	"github.com/ridge/bulldozer/bulldozer"
 )
 
 type IssueComment struct {
@@ -43,6 +43,11 @@ func handleIssueComment(config *ServerConfig, event github.IssueCommentEvent) {
 	installationID := githubapp.GetInstallationIDFromEvent(&event)
 	ctx, logger := githubapp.PreparePRContext(ctx, installationID, repo, number)
 
+	if bulldozer.IsStatusComment(event.GetComment().GetBody()) {
+		logger.Debug().Msg("Doing nothing since the comment is a bulldozer status comment")
+		return
+	}
+
 	client, err := config.ClientCreator.NewInstallationClient(installationID)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to instantiate github client")
@@ -54,9 +59,13 @@ func handleIssueComment(config *ServerConfig, event github.IssueCommentEvent) {
 		logger.Error().Err(err).Msgf("failed to get pull request %s/%s#%d", owner, repoName, number)
 		return
 	}
-	pullCtx := pull.NewGithubContext(client, pr)
+	pullCtx, err := config.NewPullContext(installationID, client, pr)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate pull request context")
+		return
+	}
 
-	if err := ProcessPullRequest(ctx, config, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+	if err := ProcessPullRequest(ctx, config, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
 		logger.Error().Err(err).Msg("Error processing pull request")
 	}
 }
@@ -0,0 +1,148 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/palantir/go-githubapp/githubapp"
+	"github.com/pkg/errors"
+
+	"github.com/ridge/bulldozer/bulldozer"
+)
+
+// MergeGroupEvent is the payload of the merge_group event, which the github
+// package does not define yet.
+type MergeGroupEvent struct {
+	// Action is "checks_requested" when GitHub creates a merge group, or
+	// "destroyed" when the merge group merged or was removed from the queue.
+	Action string `json:"action"`
+
+	// Reason is "merged", "invalidated" or "dequeued" for destroyed merge
+	// groups.
+	Reason string `json:"reason"`
+
+	MergeGroup struct {
+		HeadSHA string `json:"head_sha"`
+		HeadRef string `json:"head_ref"`
+		BaseSHA string `json:"base_sha"`
+		BaseRef string `json:"base_ref"`
+	} `json:"merge_group"`
+
+	Repo         *github.Repository   `json:"repository"`
+	Installation *github.Installation `json:"installation"`
+}
+
+func (e *MergeGroupEvent) GetInstallation() *github.Installation {
+	return e.Installation
+}
+
+type MergeGroup struct {
+	Config *ServerConfig
+}
+
+func (h *MergeGroup) Handles() []string {
+	return []string{"merge_group"}
+}
+
+func handleMergeGroup(config *ServerConfig, event MergeGroupEvent) {
+	ctx := context.Background()
+
+	repo := event.Repo
+	owner := repo.GetOwner().GetLogin()
+	repoName := repo.GetName()
+	installationID := githubapp.GetInstallationIDFromEvent(&event)
+
+	base, number, ok := bulldozer.ParseMergeGroupRef(event.MergeGroup.HeadRef)
+	if !ok {
+		_, logger := githubapp.PrepareRepoContext(ctx, installationID, repo)
+		logger.Debug().Msgf("Doing nothing since merge group ref %q does not identify a pull request", event.MergeGroup.HeadRef)
+		return
+	}
+	ctx, logger := githubapp.PreparePRContext(ctx, installationID, repo, number)
+
+	if event.Action != "destroyed" {
+		logger.Debug().Msgf("Doing nothing since merge_group action was %q instead of 'destroyed'", event.Action)
+		return
+	}
+
+	client, err := config.ClientCreator.NewInstallationClient(installationID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate github client")
+		return
+	}
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, repoName, number)
+	if err != nil {
+		logger.Error().Err(err).Msgf("failed to get pull request %s/%s#%d", owner, repoName, number)
+		return
+	}
+	pullCtx, err := config.NewPullContext(installationID, client, pr)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate pull request context")
+		return
+	}
+
+	if event.Reason == "merged" {
+		logger.Info().Msgf("Pull request merged through the merge queue of %s", base)
+
+		fetched, err := FindPRConfig(ctx, config.ConfigFetcher, client, pullCtx)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to fetch configuration")
+			return
+		}
+		if fetched == nil {
+			return
+		}
+
+		merger, err := config.NewMerger(installationID, client)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to instantiate merger")
+			return
+		}
+		bulldozer.CleanupAfterMerge(ctx, pullCtx, merger, fetched.Config.Merge)
+		return
+	}
+
+	// pull requests that were removed from the queue are not added again
+	// until they change, as they would likely fail again; invalidated merge
+	// groups are rebuilt by GitHub with the pull request still queued
+	logger.Info().Msgf("Merge group was destroyed in the merge queue of %s: %s", base, event.Reason)
+	if event.Reason == "dequeued" && config.MergeQueue != nil {
+		reason := fmt.Sprintf("pull request was removed from the GitHub merge queue (%s)", event.Reason)
+		config.MergeQueue.Reject(bulldozer.QueueKeyFor(pullCtx), number, pullCtx.HeadSHA(), reason)
+	}
+
+	if err := ProcessPullRequest(ctx, config, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+		logger.Error().Err(err).Msg("Error processing pull request")
+	}
+}
+
+func (h *MergeGroup) Handle(ctx context.Context, eventType, deliveryID string, payload []byte) error {
+	var event MergeGroupEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return errors.Wrap(err, "failed to parse merge_group event payload")
+	}
+
+	go handleMergeGroup(h.Config, event)
+
+	return nil
+}
+
+// type assertion
+var _ githubapp.EventHandler = &MergeGroup{}
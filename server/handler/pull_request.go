@@ -21,8 +21,6 @@ import (
 	"github.com/google/go-github/v43/github"
 	"github.com/palantir/go-githubapp/githubapp"
 	"github.com/pkg/errors"
-
-	"github.com/ridge/bulldozer/pull"
 )
 
 type PullRequest struct {
@@ -43,25 +41,30 @@ func handlePullRequest(config *ServerConfig, event github.PullRequestEvent) {
 	installationID := githubapp.GetInstallationIDFromEvent(&event)
 	ctx, logger := githubapp.PreparePRContext(ctx, installationID, repo, number)
 
-	if event.GetAction() == "closed" {
-		logger.Debug().Msg("Doing nothing since pull request is closed")
-		return
-	}
-
 	client, err := config.ClientCreator.NewInstallationClient(installationID)
 	if err != nil {
 		logger.Error().Err(err).Msg("failed to instantiate github client")
 		return
 	}
 
+	if event.GetAction() == "closed" {
+		logger.Debug().Msg("Doing nothing since pull request is closed")
+		removeFromQueue(ctx, config, installationID, client, event.GetPullRequest())
+		return
+	}
+
 	pr, _, err := client.PullRequests.Get(ctx, owner, repoName, number)
 	if err != nil {
 		logger.Error().Err(err).Msgf("failed to get pull request %s/%s#%d", owner, repoName, number)
 		return
 	}
-	pullCtx := pull.NewGithubContext(client, pr)
+	pullCtx, err := config.NewPullContext(installationID, client, pr)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate pull request context")
+		return
+	}
 
-	if err := ProcessPullRequest(ctx, config, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+	if err := ProcessPullRequest(ctx, config, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
 		logger.Error().Err(err).Msg("Error updating pull request")
 	}
 
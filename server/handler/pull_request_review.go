@@ -21,8 +21,6 @@ import (
 	"github.com/google/go-github/v43/github"
 	"github.com/palantir/go-githubapp/githubapp"
 	"github.com/pkg/errors"
-
-	"github.com/ridge/bulldozer/pull"
 )
 
 type PullRequestReview struct {
@@ -54,9 +52,13 @@ func handlePullRequestReview(config *ServerConfig, event github.PullRequestRevie
 		logger.Error().Err(err).Msgf("failed to get pull request %s/%s#%d", owner, repoName, number)
 		return
 	}
-	pullCtx := pull.NewGithubContext(client, pr)
+	pullCtx, err := config.NewPullContext(installationID, client, pr)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate pull request context")
+		return
+	}
 
-	if err := ProcessPullRequest(ctx, config, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+	if err := ProcessPullRequest(ctx, config, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
 		logger.Error().Err(err).Msg("Error updating pull request")
 	}
 
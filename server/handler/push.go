@@ -72,11 +72,15 @@ func handlePush(config *ServerConfig, event github.PushEvent) {
 	}
 
 	for _, pr := range prs {
-		pullCtx := pull.NewGithubContext(client, pr)
+		pullCtx, err := config.NewPullContext(installationID, client, pr)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to instantiate pull request context")
+			continue
+		}
 		logger := logger.With().Int(githubapp.LogKeyPRNum, pr.GetNumber()).Logger()
 
 		logger.Debug().Msgf("checking status for updated sha %s", baseRef)
-		if err := ProcessPullRequest(logger.WithContext(ctx), config, pullCtx, client, baseRef); err != nil {
+		if err := ProcessPullRequest(logger.WithContext(ctx), config, installationID, pullCtx, client, baseRef); err != nil {
 			logger.Error().Err(err).Msg("Error updating pull request")
 		}
 	}
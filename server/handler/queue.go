@@ -0,0 +1,92 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/palantir/go-baseapp/baseapp"
+	"github.com/rs/zerolog"
+
+	"github.com/ridge/bulldozer/bulldozer"
+)
+
+// Queue returns a handler listing the merge queues of this server instance to
+// requests that authenticate with the bearer token.
+func Queue(queue *bulldozer.MergeQueue, token string) http.Handler {
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			baseapp.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+			return
+		}
+		baseapp.WriteJSON(w, http.StatusOK, queue.Snapshot())
+	})
+}
+
+// advanceQueue processes the new head of a merge queue, which otherwise waits
+// for an event on its pull request.
+func advanceQueue(ctx context.Context, serverConfig *ServerConfig, installationID int64, client *github.Client, key bulldozer.QueueKey, next bulldozer.QueueEntry) {
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Advancing merge queue %s to #%d", key, next.Number)
+
+	go reprocessPullRequest(logger.WithContext(context.Background()), serverConfig, installationID, client, key.Owner, key.Repo, next.Number)
+}
+
+// removeFromQueue removes a closed pull request from the merge queue of its
+// target branch.
+func removeFromQueue(ctx context.Context, serverConfig *ServerConfig, installationID int64, client *github.Client, pr *github.PullRequest) {
+	if serverConfig.MergeQueue == nil {
+		return
+	}
+
+	key := bulldozer.QueueKey{
+		Owner: pr.GetBase().GetRepo().GetOwner().GetLogin(),
+		Repo:  pr.GetBase().GetRepo().GetName(),
+		Base:  pr.GetBase().GetRef(),
+	}
+	if next, ok := serverConfig.MergeQueue.Remove(key, pr.GetNumber()); ok {
+		advanceQueue(ctx, serverConfig, installationID, client, key, next)
+	}
+}
+
+// processBatchStatus evaluates the batch of a merge queue if the commit is
+// its staging commit, as statuses on the staging branch belong to no pull
+// request. It returns true if the commit belongs to a batch.
+func processBatchStatus(ctx context.Context, serverConfig *ServerConfig, installationID int64, owner, repo, sha string) bool {
+	if serverConfig.MergeQueue == nil {
+		return false
+	}
+
+	key, head, ok := serverConfig.MergeQueue.BatchForCommit(owner, repo, sha)
+	if !ok {
+		return false
+	}
+
+	logger := zerolog.Ctx(ctx)
+	logger.Debug().Msgf("Evaluating merge queue %s for status change on its staging branch", key)
+
+	client, err := serverConfig.ClientCreator.NewInstallationClient(installationID)
+	if err != nil {
+		logger.Error().Err(err).Msg("failed to instantiate github client")
+		return true
+	}
+
+	reprocessPullRequest(ctx, serverConfig, installationID, client, key.Owner, key.Repo, head.Number)
+	return true
+}
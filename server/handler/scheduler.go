@@ -0,0 +1,149 @@
+// Copyright 2026 Tectonic Labs, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package handler
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v43/github"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+
+	"github.com/ridge/bulldozer/bulldozer"
+	"github.com/ridge/bulldozer/pull"
+)
+
+// Scheduler re-evaluates pull requests when the schedule of their
+// configuration opens, so that they do not wait for another webhook.
+// Wakeups are kept in memory and are lost when the server restarts.
+type Scheduler struct {
+	mu      sync.Mutex
+	wakeups map[string]*wakeup
+}
+
+type wakeup struct {
+	at    time.Time
+	timer *time.Timer
+}
+
+func NewScheduler() *Scheduler {
+	return &Scheduler{
+		wakeups: make(map[string]*wakeup),
+	}
+}
+
+// Schedule calls fn at time at. Each key has at most one pending wakeup: if
+// the key already has a wakeup at or before at, fn is dropped, otherwise the
+// existing wakeup is replaced.
+func (s *Scheduler) Schedule(key string, at time.Time, fn func()) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if w, ok := s.wakeups[key]; ok {
+		if !w.at.After(at) {
+			return false
+		}
+		w.timer.Stop()
+	}
+
+	w := &wakeup{at: at}
+	w.timer = time.AfterFunc(time.Until(at), func() {
+		s.mu.Lock()
+		if s.wakeups[key] == w {
+			delete(s.wakeups, key)
+		}
+		s.mu.Unlock()
+
+		fn()
+	})
+	s.wakeups[key] = w
+	return true
+}
+
+// nextScheduleOpening returns the earliest time at which a currently closed
+// schedule of the configuration opens, or the zero time if there is none.
+func nextScheduleOpening(prConfig bulldozer.Config, t time.Time) (time.Time, error) {
+	var next time.Time
+	for _, schedule := range []bulldozer.Schedule{prConfig.Merge.Schedule, prConfig.Update.Schedule} {
+		if !schedule.Enabled() {
+			continue
+		}
+
+		at, ok, err := schedule.NextOpening(t)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if ok && at.After(t) && (next.IsZero() || at.Before(next)) {
+			next = at
+		}
+	}
+	return next, nil
+}
+
+func scheduleReevaluation(ctx context.Context, serverConfig *ServerConfig, installationID int64, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client) error {
+	if serverConfig.Scheduler == nil {
+		return nil
+	}
+
+	logger := zerolog.Ctx(ctx)
+
+	at, err := nextScheduleOpening(prConfig, time.Now())
+	if err != nil {
+		return errors.Wrap(err, "failed to determine next schedule opening")
+	}
+	if at.IsZero() {
+		return nil
+	}
+
+	owner, repo, number := pullCtx.Owner(), pullCtx.Repo(), pullCtx.Number()
+	scheduled := serverConfig.Scheduler.Schedule(pullCtx.Locator(), at, func() {
+		reprocessPullRequest(logger.WithContext(context.Background()), serverConfig, installationID, client, owner, repo, number)
+	})
+	if scheduled {
+		logger.Debug().Msgf("Scheduled re-evaluation of %s at %s", pullCtx.Locator(), at.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// reprocessPullRequest fetches the current state of the pull request and
+// processes it, outside of the handling of a webhook. Closed pull requests are
+// removed from the merge queue instead.
+func reprocessPullRequest(ctx context.Context, serverConfig *ServerConfig, installationID int64, client *github.Client, owner, repo string, number int) {
+	logger := zerolog.Ctx(ctx)
+
+	pr, _, err := client.PullRequests.Get(ctx, owner, repo, number)
+	if err != nil {
+		logger.Error().Err(errors.WithStack(err)).Msgf("Failed to retrieve pull request %s/%s#%d", owner, repo, number)
+		return
+	}
+	if pr.GetState() == "closed" {
+		// the event that closed the pull request may have been missed or
+		// delivered to another instance, so the queue would wait for it forever
+		logger.Debug().Msgf("Doing nothing since pull request %s/%s#%d was closed", owner, repo, number)
+		removeFromQueue(ctx, serverConfig, installationID, client, pr)
+		return
+	}
+
+	pullCtx, err := serverConfig.NewPullContext(installationID, client, pr)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to instantiate pull request context")
+		return
+	}
+	if err := ProcessPullRequest(ctx, serverConfig, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+		logger.Error().Err(err).Msgf("Error processing pull request %s/%s#%d", owner, repo, number)
+	}
+}
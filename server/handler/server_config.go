@@ -31,6 +31,86 @@ type ServerConfig struct {
 	bulldozer.ConfigFetcher
 
 	PushRestrictionUserToken string
+
+	// Scheduler re-evaluates pull requests when merge or update schedules
+	// open. If nil, pull requests wait for the next event instead.
+	Scheduler *Scheduler
+
+	// AppID is the ID of the GitHub App, used to identify the check runs
+	// created by bulldozer.
+	AppID int64
+
+	// Reporting selects how bulldozer reports its evaluation on pull
+	// requests. It is one of the bulldozer.Report* constants.
+	Reporting string
+
+	// MergeQueue holds the merge queues of repositories that enable them.
+	MergeQueue *bulldozer.MergeQueue
+
+	// NativeQueues remembers the target branches that do not require a
+	// GitHub merge queue. If nil, every merge looks up the merge queue of
+	// the target branch.
+	NativeQueues *bulldozer.NativeQueueCache
+
+	// PullRequestAPI selects the GitHub API used to load information about
+	// pull requests. It is one of the PullRequestAPI* constants.
+	PullRequestAPI string
+}
+
+const (
+	// PullRequestAPIREST loads pull requests with the REST API.
+	PullRequestAPIREST = "rest"
+
+	// PullRequestAPIGraphQL loads most information about pull requests with
+	// a single GraphQL query, which uses less of the rate limit.
+	PullRequestAPIGraphQL = "graphql"
+)
+
+// CheckRunName is the name of the check run bulldozer reports with.
+const CheckRunName = "bulldozer"
+
+// reportCheckRun returns the check run bulldozer reports with, or the zero
+// value if it does not report with a check run.
+func (c *ServerConfig) reportCheckRun() bulldozer.CheckRunID {
+	if c.Reporting != bulldozer.ReportCheckRun {
+		return bulldozer.CheckRunID{}
+	}
+	return bulldozer.CheckRunID{Name: CheckRunName, AppID: c.AppID}
+}
+
+func (c *ServerConfig) NewReporter(client *github.Client) bulldozer.Reporter {
+	switch c.Reporting {
+	case bulldozer.ReportCheckRun:
+		return bulldozer.NewCheckRunReporter(client, c.AppID, CheckRunName)
+	case bulldozer.ReportComment:
+		return bulldozer.NewCommentReporter(client)
+	default:
+		return bulldozer.NopReporter{}
+	}
+}
+
+// NewPullContext returns a pull.Context for a pull request of the
+// installation that uses the configured API.
+func (c *ServerConfig) NewPullContext(installationID int64, client *github.Client, pr *github.PullRequest) (pull.Context, error) {
+	if c.PullRequestAPI != PullRequestAPIGraphQL {
+		return pull.NewGithubContext(client, pr), nil
+	}
+
+	v4client, err := c.ClientCreator.NewInstallationV4Client(installationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to instantiate github v4 client")
+	}
+	return pull.NewGraphQLContext(client, v4client, pr), nil
+}
+
+// NewMerger returns a Merger for the installation, which adds pull requests
+// to the merge queue of GitHub if their target branch requires one.
+func (c *ServerConfig) NewMerger(installationID int64, client *github.Client) (bulldozer.Merger, error) {
+	v4client, err := c.ClientCreator.NewInstallationV4Client(installationID)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to instantiate github v4 client")
+	}
+	return bulldozer.NewNativeQueueMerger(bulldozer.NewGitHubMerger(client), v4client, c.NativeQueues), nil
 }
 
 func FindPRConfig(ctx context.Context, configFetcher bulldozer.ConfigFetcher, client *github.Client, pullCtx pull.Context) (*bulldozer.FetchedConfig, error) {
@@ -53,41 +133,82 @@ func FindPRConfig(ctx context.Context, configFetcher bulldozer.ConfigFetcher, cl
 	}
 }
 
-func UpdatePullRequest(ctx context.Context, serverConfig *ServerConfig, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client, baseRef string) error {
-	shouldUpdate, err := bulldozer.ShouldUpdatePR(ctx, pullCtx, prConfig.Update)
+func UpdatePullRequest(ctx context.Context, serverConfig *ServerConfig, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client, baseRef string) (*bulldozer.Decision, error) {
+	decision, err := bulldozer.ShouldUpdatePR(ctx, pullCtx, prConfig.Update)
 	if err != nil {
-		return errors.Wrap(err, "unable to determine update status")
+		return nil, errors.Wrap(err, "unable to determine update status")
 	}
 
-	if !shouldUpdate {
-		return nil
+	if !decision.Passed() {
+		return decision, nil
 	}
 
 	if err := bulldozer.UpdatePR(ctx, pullCtx, client, prConfig.Update, baseRef); err != nil {
-		return errors.Wrap(err, "failed to update pull request")
+		return decision, errors.Wrap(err, "failed to update pull request")
 	}
 
-	return nil
+	return decision, nil
 }
 
-func MergePullRequest(ctx context.Context, serverConfig *ServerConfig, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client) error {
-	shouldMerge, err := bulldozer.ShouldMergePR(ctx, pullCtx, prConfig.Merge)
+// MergePullRequest merges the pull request if it satisfies the merge
+// conditions. The report, which may contain the outcome of updating the pull
+// request, is completed with the merge decision and published.
+func MergePullRequest(ctx context.Context, serverConfig *ServerConfig, installationID int64, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client, report bulldozer.Report) error {
+	logger := zerolog.Ctx(ctx)
+
+	decision, err := bulldozer.ShouldMergePR(ctx, pullCtx, prConfig.Merge)
 	if err != nil {
 		return errors.Wrap(err, "unable to determine merge status")
 	}
-	if !shouldMerge {
-		return nil
+
+	if serverConfig.MergeQueue != nil {
+		bulldozer.EvaluateRejection(serverConfig.MergeQueue, pullCtx, decision)
 	}
 
-	merger := bulldozer.NewGitHubMerger(client)
-	if serverConfig.PushRestrictionUserToken != "" {
-		tokenClient, err := serverConfig.NewTokenClient(serverConfig.PushRestrictionUserToken)
+	queued := prConfig.Merge.Queue.Enabled && serverConfig.MergeQueue != nil
+	if queued {
+		next, advance, err := bulldozer.EvaluateQueue(ctx, serverConfig.MergeQueue, pullCtx, client, prConfig.Merge, decision)
 		if err != nil {
-			return errors.Wrap(err, "failed to create token client")
+			return errors.Wrap(err, "unable to evaluate merge queue")
 		}
-		merger = bulldozer.NewPushRestrictionMerger(merger, bulldozer.NewGitHubMerger(tokenClient))
+		if advance {
+			advanceQueue(ctx, serverConfig, installationID, client, bulldozer.QueueKeyFor(pullCtx), next)
+		}
+	}
+
+	report.Merge = decision
+	reporter := serverConfig.NewReporter(client)
+
+	if batch := decision.MergedBatch; batch != nil {
+		report.MergeAttempt = &bulldozer.MergeAttempt{Merged: true, SHA: batch.SHA}
+		if err := reporter.Report(ctx, pullCtx, report); err != nil {
+			logger.Error().Err(err).Msg("Failed to report evaluation")
+		}
+		return cleanupAfterBatch(ctx, serverConfig, installationID, prConfig, pullCtx, client, batch)
 	}
 
+	if prConfig.Merge.AutoMerge {
+		return autoMergePullRequest(ctx, serverConfig, installationID, prConfig, pullCtx, client, reporter, report)
+	}
+
+	if err := reporter.Report(ctx, pullCtx, report); err != nil {
+		logger.Error().Err(err).Msg("Failed to report evaluation")
+	}
+
+	if !decision.Passed() {
+		return nil
+	}
+
+	merger, err := newPullRequestMerger(serverConfig, installationID, client)
+	if err != nil {
+		return err
+	}
+	merger = bulldozer.NewReportingMerger(merger, reporter, report)
+	if queued {
+		merger = newQueueMerger(ctx, serverConfig, installationID, pullCtx, client, merger)
+	}
+	merger = newReevaluatingMerger(ctx, serverConfig, installationID, pullCtx, client, merger)
+
 	if err := bulldozer.MergePR(ctx, pullCtx, merger, prConfig.Merge); err != nil {
 		return errors.Wrap(err, "failed to merge pull request")
 	}
@@ -95,7 +216,99 @@ func MergePullRequest(ctx context.Context, serverConfig *ServerConfig, prConfig
 	return nil
 }
 
-func ProcessPullRequest(ctx context.Context, serverConfig *ServerConfig, pullCtx pull.Context, client *github.Client, baseRef string) error {
+// cleanupAfterBatch runs the cleanup after merge for every pull request of a
+// batch that the merge queue merged by fast-forwarding the target branch. The
+// pull requests share the target branch, and so the configuration.
+func cleanupAfterBatch(ctx context.Context, serverConfig *ServerConfig, installationID int64, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client, batch *bulldozer.MergedBatch) error {
+	logger := zerolog.Ctx(ctx)
+
+	merger, err := newPullRequestMerger(serverConfig, installationID, client)
+	if err != nil {
+		return err
+	}
+
+	for _, number := range batch.Numbers {
+		memberCtx := pullCtx
+		if number != pullCtx.Number() {
+			pr, _, err := client.PullRequests.Get(ctx, pullCtx.Owner(), pullCtx.Repo(), number)
+			if err != nil {
+				logger.Error().Err(err).Msgf("failed to get pull request %s/%s#%d", pullCtx.Owner(), pullCtx.Repo(), number)
+				continue
+			}
+			if memberCtx, err = serverConfig.NewPullContext(installationID, client, pr); err != nil {
+				logger.Error().Err(err).Msg("failed to instantiate pull request context")
+				continue
+			}
+		}
+		bulldozer.CleanupAfterMerge(ctx, memberCtx, merger, prConfig.Merge)
+	}
+	return nil
+}
+
+// autoMergePullRequest enables or disables auto-merge on the pull request
+// depending on the decision in the report, and reports the outcome.
+func autoMergePullRequest(ctx context.Context, serverConfig *ServerConfig, installationID int64, prConfig bulldozer.Config, pullCtx pull.Context, client *github.Client, reporter bulldozer.Reporter, report bulldozer.Report) error {
+	logger := zerolog.Ctx(ctx)
+
+	v4client, err := serverConfig.ClientCreator.NewInstallationV4Client(installationID)
+	if err != nil {
+		return errors.Wrap(err, "failed to instantiate github v4 client")
+	}
+
+	merger, err := newPullRequestMerger(serverConfig, installationID, client)
+	if err != nil {
+		return err
+	}
+	if prConfig.Merge.Queue.Enabled && serverConfig.MergeQueue != nil {
+		merger = newQueueMerger(ctx, serverConfig, installationID, pullCtx, client, merger)
+	}
+	merger = newReevaluatingMerger(ctx, serverConfig, installationID, pullCtx, client, merger)
+
+	attempt, mergeErr := bulldozer.AutoMergePR(ctx, pullCtx, bulldozer.NewAutoMerger(v4client), merger, prConfig.Merge, report.Merge)
+	report.MergeAttempt = attempt
+	if err := reporter.Report(ctx, pullCtx, report); err != nil {
+		logger.Error().Err(err).Msg("Failed to report evaluation")
+	}
+
+	return errors.Wrap(mergeErr, "failed to auto-merge pull request")
+}
+
+// newPullRequestMerger returns the Merger for pull requests of the
+// installation, which uses the push restriction token if it is configured.
+func newPullRequestMerger(serverConfig *ServerConfig, installationID int64, client *github.Client) (bulldozer.Merger, error) {
+	merger, err := serverConfig.NewMerger(installationID, client)
+	if err != nil {
+		return nil, err
+	}
+	if serverConfig.PushRestrictionUserToken != "" {
+		tokenClient, err := serverConfig.NewTokenClient(serverConfig.PushRestrictionUserToken)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create token client")
+		}
+		merger = bulldozer.NewPushRestrictionMerger(merger, bulldozer.NewGitHubMerger(tokenClient))
+	}
+	return merger, nil
+}
+
+// newQueueMerger wraps the merger to advance the merge queue of the target
+// branch of the pull request after merges.
+func newQueueMerger(ctx context.Context, serverConfig *ServerConfig, installationID int64, pullCtx pull.Context, client *github.Client, merger bulldozer.Merger) bulldozer.Merger {
+	key := bulldozer.QueueKeyFor(pullCtx)
+	return bulldozer.NewQueueMerger(merger, serverConfig.MergeQueue, func(next bulldozer.QueueEntry) {
+		advanceQueue(ctx, serverConfig, installationID, client, key, next)
+	})
+}
+
+// newReevaluatingMerger wraps the merger to evaluate the pull request again
+// if its head changed before the merge.
+func newReevaluatingMerger(ctx context.Context, serverConfig *ServerConfig, installationID int64, pullCtx pull.Context, client *github.Client, merger bulldozer.Merger) bulldozer.Merger {
+	logger := zerolog.Ctx(ctx)
+	return bulldozer.NewReevaluatingMerger(merger, func() {
+		go reprocessPullRequest(logger.WithContext(context.Background()), serverConfig, installationID, client, pullCtx.Owner(), pullCtx.Repo(), pullCtx.Number())
+	})
+}
+
+func ProcessPullRequest(ctx context.Context, serverConfig *ServerConfig, installationID int64, pullCtx pull.Context, client *github.Client, baseRef string) error {
 	logger := zerolog.Ctx(ctx)
 
 	bulldozerConfig, err := FindPRConfig(ctx, serverConfig.ConfigFetcher, client, pullCtx)
@@ -108,10 +321,18 @@ func ProcessPullRequest(ctx context.Context, serverConfig *ServerConfig, pullCtx
 
 	logger.Debug().Msgf("Found valid configuration for %s", bulldozerConfig)
 	prConfig := *bulldozerConfig.Config
+	prConfig.Merge.ReportCheckRun = serverConfig.reportCheckRun()
+	prConfig.Update.ReportCheckRun = serverConfig.reportCheckRun()
 
-	if err := UpdatePullRequest(ctx, serverConfig, prConfig, pullCtx, client, baseRef); err != nil {
+	if err := scheduleReevaluation(ctx, serverConfig, installationID, prConfig, pullCtx, client); err != nil {
+		logger.Error().Err(err).Msg("Failed to schedule re-evaluation")
+	}
+
+	var report bulldozer.Report
+	report.Update, err = UpdatePullRequest(ctx, serverConfig, prConfig, pullCtx, client, baseRef)
+	if err != nil {
 		logger.Error().Err(err).Msg("Update failed")
 	}
 
-	return MergePullRequest(ctx, serverConfig, prConfig, pullCtx, client)
+	return MergePullRequest(ctx, serverConfig, installationID, prConfig, pullCtx, client, report)
 }
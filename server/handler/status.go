@@ -42,6 +42,12 @@ func handleStatus(config *ServerConfig, event github.StatusEvent) {
 	installationID := githubapp.GetInstallationIDFromEvent(&event)
 	ctx, logger := githubapp.PrepareRepoContext(ctx, installationID, repo)
 
+	// failures on the staging branch of a merge queue split the batch, so
+	// they are evaluated as well
+	if processBatchStatus(ctx, config, installationID, owner, repoName, event.GetSHA()) {
+		return
+	}
+
 	if event.GetState() != "success" {
 		logger.Debug().Msgf("Doing nothing since context state for %q was %q", event.GetContext(), event.GetState())
 		return
@@ -65,10 +71,14 @@ func handleStatus(config *ServerConfig, event github.StatusEvent) {
 	}
 
 	for _, pr := range prs {
-		pullCtx := pull.NewGithubContext(client, pr)
+		pullCtx, err := config.NewPullContext(installationID, client, pr)
+		if err != nil {
+			logger.Error().Err(err).Msg("failed to instantiate pull request context")
+			continue
+		}
 		logger := logger.With().Int(githubapp.LogKeyPRNum, pr.GetNumber()).Logger()
 
-		if err := ProcessPullRequest(ctx, config, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+		if err := ProcessPullRequest(ctx, config, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
 			logger.Error().Err(err).Msg("Error updating pull request")
 		}
 	}
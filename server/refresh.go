@@ -29,7 +29,7 @@ func listRepos(ctx context.Context, client *github.Client) ([]*github.Repository
 	return repositories, nil
 }
 
-func refreshRepo(ctx context.Context, serverConfig *handler.ServerConfig, repo *github.Repository, client *github.Client, logger zerolog.Logger) {
+func refreshRepo(ctx context.Context, serverConfig *handler.ServerConfig, installationID int64, repo *github.Repository, client *github.Client, logger zerolog.Logger) {
 	prs, err := pull.ListOpenPullRequests(ctx, client, repo.GetOwner().GetLogin(), repo.GetName())
 	if err != nil {
 		logger.Warn().Err(errors.WithStack(err)).Msgf("Error enumerating all PRs in repository %s", repo.GetFullName())
@@ -38,9 +38,13 @@ func refreshRepo(ctx context.Context, serverConfig *handler.ServerConfig, repo *
 
 	for _, pr := range prs {
 		logger.Debug().Msgf("Handling %s#%d", repo.GetFullName(), pr.GetNumber())
-		pullCtx := pull.NewGithubContext(client, pr)
+		pullCtx, err := serverConfig.NewPullContext(installationID, client, pr)
+		if err != nil {
+			logger.Warn().Err(err).Msgf("Error creating context for PR %d in repository %s", pr.GetNumber(), repo.GetFullName())
+			continue
+		}
 
-		if err := handler.ProcessPullRequest(ctx, serverConfig, pullCtx, client, pr.GetBase().GetRef()); err != nil {
+		if err := handler.ProcessPullRequest(ctx, serverConfig, installationID, pullCtx, client, pr.GetBase().GetRef()); err != nil {
 			logger.Warn().Err(errors.WithStack(err)).Msgf("Error processing PR %d in repository %s", pr.GetNumber(), repo.GetFullName())
 		}
 		logger.Debug().Msgf("Finished handling %s#%d", repo.GetFullName(), pr.GetNumber())
@@ -76,7 +80,7 @@ func refresh(serverConfig *handler.ServerConfig, clientCreator githubapp.ClientC
 
 		for _, repo := range repos {
 			logger.Debug().Msgf("Handling repository %s of installation %d", repo.GetFullName(), installation.ID)
-			refreshRepo(logger.WithContext(context.Background()), serverConfig, repo, ic, logger)
+			refreshRepo(logger.WithContext(context.Background()), serverConfig, installation.ID, repo, ic, logger)
 			logger.Debug().Msgf("Finished handling repository %s of installation %d", repo.GetFullName(), installation.ID)
 		}
 
@@ -73,16 +73,43 @@ func New(c *Config) (*Server, error) {
 		return nil, errors.Wrap(err, "failed to initialize Github client creator")
 	}
 
+	reporting := c.Options.Reporting
+	switch reporting {
+	case "":
+		reporting = bulldozer.ReportCheckRun
+	case bulldozer.ReportCheckRun, bulldozer.ReportComment, bulldozer.ReportNone:
+	default:
+		return nil, errors.Errorf("invalid reporting option %q, expected one of %s, %s, %s", reporting, bulldozer.ReportCheckRun, bulldozer.ReportComment, bulldozer.ReportNone)
+	}
+
+	pullRequestAPI := c.Options.PullRequestAPI
+	switch pullRequestAPI {
+	case "":
+		pullRequestAPI = handler.PullRequestAPIREST
+	case handler.PullRequestAPIREST, handler.PullRequestAPIGraphQL:
+	default:
+		return nil, errors.Errorf("invalid pull_request_api option %q, expected one of %s, %s", pullRequestAPI, handler.PullRequestAPIREST, handler.PullRequestAPIGraphQL)
+	}
+
 	serverConfig := &handler.ServerConfig{
 		ClientCreator: clientCreator,
 		ConfigFetcher: bulldozer.NewConfigFetcher(c.Options.ConfigurationPath, c.Options.DefaultRepositoryConfig),
 
 		PushRestrictionUserToken: c.Options.PushRestrictionUserToken,
+
+		Scheduler:    handler.NewScheduler(),
+		MergeQueue:   bulldozer.NewMergeQueue(),
+		NativeQueues: bulldozer.NewNativeQueueCache(bulldozer.DefaultNativeQueueCacheTTL),
+
+		AppID:          c.Github.App.IntegrationID,
+		Reporting:      reporting,
+		PullRequestAPI: pullRequestAPI,
 	}
 
 	webhookHandler := githubapp.NewDefaultEventDispatcher(c.Github,
 		&handler.CheckRun{Config: serverConfig},
 		&handler.IssueComment{Config: serverConfig},
+		&handler.MergeGroup{Config: serverConfig},
 		&handler.PullRequest{Config: serverConfig},
 		&handler.PullRequestReview{Config: serverConfig},
 		&handler.Push{Config: serverConfig},
@@ -96,6 +123,9 @@ func New(c *Config) (*Server, error) {
 
 	// any additional API routes
 	mux.Handle(pat.Get("/api/health"), handler.Health())
+	if c.Options.QueueAPIToken != "" {
+		mux.Handle(pat.Get("/api/queue"), handler.Queue(serverConfig.MergeQueue, c.Options.QueueAPIToken))
+	}
 
 	return &Server{
 		config:        c,